@@ -0,0 +1,99 @@
+package pools
+
+import "context"
+
+// guardCallbacks wraps the user supplied callbacks of the pool, so a callback which panics is recovered
+// and logged, rather than taking down the pool thread.
+// Callbacks should not panic, but the pool continues should one do so.
+func (p *pool[T]) guardCallbacks() {
+	if onEvict := p.onEvict; onEvict != nil {
+		p.onEvict = func(t T) {
+			p.recoverCallback("OnEvict", func() {
+				onEvict(t)
+			})
+		}
+	}
+	if sizer := p.sizer; sizer != nil {
+		p.sizer = func(t T) (size uint64) {
+			// a sizer which panics measures the element as zero
+			p.recoverCallback("Sizer", func() {
+				size = sizer(t)
+			})
+			return size
+		}
+	} else if isSizer[T]() {
+		// an element's own Sizer is guarded in the same way, the built in measures, which can not panic, are not
+		p.sizer = func(t T) (size uint64) {
+			p.recoverCallback("PoolSize", func() {
+				size = elementSize(t)
+			})
+			return size
+		}
+	}
+	if keyOf := p.keyOf; keyOf != nil {
+		p.keyOf = func(t T) (key any) {
+			// an element whose key function panics is held without a key, so replaces, and is replaced by, no other
+			key = noKey{}
+			p.recoverCallback("Key", func() {
+				key = keyOf(t)
+			})
+			return key
+		}
+	}
+	if onDeliver := p.onDeliver; onDeliver != nil {
+		p.onDeliver = func(ctx context.Context, t T) {
+			p.recoverCallback("OnDeliver", func() {
+				onDeliver(ctx, t)
+			})
+		}
+	}
+	if onClose := p.onClose; onClose != nil {
+		p.onClose = func(final PoolStats) {
+			p.recoverCallback("OnClose", func() {
+				onClose(final)
+			})
+		}
+	}
+	if p.retention != nil {
+		p.retention = guardedRetention[T]{p: p, retention: p.retention}
+	}
+}
+
+// recoverCallback calls the given callback, logging any panic it raises.
+func (p *pool[T]) recoverCallback(name string, callback func()) {
+	recoverCallback(p.logger, name, callback)
+}
+
+// recoverCallback calls the given callback, logging any panic it raises to the given logger.
+func recoverCallback(logger Logger, name string, callback func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Printf("%s callback panicked: %v\n", name, r)
+		}
+	}()
+	callback()
+}
+
+// loggerOf returns the Logger of the given pool, for the callbacks of types built on a pool to log with.
+// A pool of another implementation is given a Logger which discards all messages.
+func loggerOf[T any](p Pool[T]) Logger {
+	if pl, ok := p.(*pool[T]); ok {
+		return pl.logger
+	}
+	return nopLogger{}
+}
+
+// noKey is the key of an element whose key function panicked.
+type noKey struct{}
+
+// guardedRetention recovers any panic raised by the RetentionPolicy it wraps.
+type guardedRetention[T any] struct {
+	p         *pool[T]
+	retention RetentionPolicy[T]
+}
+
+func (g guardedRetention[T]) Evict(data RetentionData[T]) {
+	g.p.recoverCallback("RetentionPolicy", func() {
+		g.retention.Evict(data)
+	})
+}
@@ -0,0 +1,108 @@
+package pools
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Cursor reads the elements of a pool one at a time, tracking the offset of the next element to be read.
+type Cursor[T any] interface {
+	// Next blocks until the next element is available, returning false once the cursor has ended.
+	Next() (T, bool)
+	// Offset returns the offset following the element last returned by Next, from which the next element is read.
+	Offset() int64
+	// Err returns the error which ended the cursor, or nil if it has not ended or was ended by its context.
+	Err() error
+	// Seek moves the cursor, forward or back, so the next call to Next returns the element at the new offset, which is
+	// returned. As with io.Seeker, whence is io.SeekStart for an absolute offset, io.SeekCurrent for an offset relative
+	// to the cursor, or io.SeekEnd for one relative to the NextOffset of the pool. An error is returned, leaving the
+	// cursor where it was, if the new offset has been evicted, or is negative, or the pool has shutdown.
+	// As with Next, Seek must not be called concurrently with the other methods of the cursor.
+	// Seek takes the form of io.Seeker, rather than a plain Seek(offset int) error, so a Cursor may be used as an
+	// io.Seeker, offsets keep the int64 of the rest of the pool, and a cursor may be moved relative to where it is.
+	// Seek(offset, io.SeekStart) moves to an absolute offset.
+	Seek(offset int64, whence int) (int64, error)
+}
+
+type cursor[T any] struct {
+	p      pool[T]
+	ctx    context.Context // context of the cursor, outliving each read
+	cancel context.CancelFunc
+	ch     <-chan Indexed[T]
+	errc   <-chan error
+	offset int64
+	err    error
+}
+
+// newCursor creates a cursor, starting a read from the given offset, which must already be resolved.
+func newCursor[T any](ctx context.Context, p pool[T], offset int64) *cursor[T] {
+	c := &cursor[T]{p: p, ctx: ctx}
+	c.start(offset)
+	return c
+}
+
+// start begins a new read from the given offset, with a context of its own, so it may be ended by a Seek.
+func (c *cursor[T]) start(offset int64) {
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(c.ctx)
+	ch := make(chan Indexed[T])
+	_, c.errc = c.p.read(ctx, offset, readConfig[T]{indexed: ch})
+	c.ch = ch
+	c.offset = offset
+	c.err = nil
+}
+
+func (c *cursor[T]) Next() (T, bool) {
+	e, ok := <-c.ch
+	if !ok {
+		if c.errc != nil {
+			c.err = <-c.errc
+			c.errc = nil
+		}
+		return e.Value, false
+	}
+	// taken from the element, as removed elements, which are passed over, leave gaps in the offsets
+	c.offset = e.Offset + 1
+	return e.Value, true
+}
+
+func (c *cursor[T]) Offset() int64 {
+	return c.offset
+}
+
+func (c *cursor[T]) Err() error {
+	return c.err
+}
+
+func (c *cursor[T]) Seek(offset int64, whence int) (int64, error) {
+	var err error
+	if !c.p.query(func(data *offsetData[T]) {
+		switch whence {
+		case io.SeekStart:
+		case io.SeekCurrent:
+			offset += c.offset
+		case io.SeekEnd:
+			offset += data.NextOffset()
+		default:
+			err = fmt.Errorf("seek failed as whence %d is not known", whence)
+			return
+		}
+		if offset < 0 {
+			err = fmt.Errorf("seek failed as offset %d is negative", offset)
+		} else if offset < data.Offset() {
+			err = fmt.Errorf("%w: offset %d, first available is %d", ErrOffsetEvicted, offset, data.Offset())
+		}
+	}) {
+		return c.offset, fmt.Errorf("seek failed as %w", ErrPoolClosed)
+	}
+	if err != nil {
+		return c.offset, err
+	}
+	// the current read is ended, and waited on, before reading again
+	c.cancel()
+	for range c.ch {
+	}
+	c.start(offset)
+	return offset, nil
+}
@@ -0,0 +1,130 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCursor_IndependentCursors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 100}, 10, 11, 12)
+	c1 := p.Cursor(ctx, -1)
+	c2 := p.Cursor(ctx, 1)
+	if c1.Offset() != 0 || c2.Offset() != 1 {
+		t.Fatalf("expected cursors at 0 and 1, found %d and %d", c1.Offset(), c2.Offset())
+	}
+	for want := 10; want < 13; want++ {
+		if v, ok := c1.Next(); !ok || v != want {
+			t.Fatalf("expected %d, found %d, %v", want, v, ok)
+		}
+		if c1.Offset() != int64(want-9) {
+			t.Fatalf("expected the cursor to advance to %d, found %d", want-9, c1.Offset())
+		}
+	}
+	if v, ok := c2.Next(); !ok || v != 11 || c2.Offset() != 2 {
+		t.Fatalf("expected the second cursor to read 11 and advance to 2, found %d at %d", v, c2.Offset())
+	}
+}
+
+func TestCursor_EndsOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 100}, 1)
+	c := p.Cursor(ctx, 0)
+	if _, ok := c.Next(); !ok {
+		t.Fatal("expected the first element")
+	}
+	p.Close()
+	if _, ok := c.Next(); ok {
+		t.Fatal("expected the cursor to end")
+	}
+	if err := c.Err(); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, found %v", err)
+	}
+}
+
+func TestCursor_IsSeeker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	var s io.Seeker = p.Cursor(ctx, 0)
+	if off, err := s.Seek(2, io.SeekStart); err != nil || off != 2 {
+		t.Fatalf("expected to seek to 2, found %d, %v", off, err)
+	}
+}
+
+func TestCursor_Seek(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 8}, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	c := p.Cursor(ctx, -1)
+	if v, _ := c.Next(); v != 2 {
+		t.Fatalf("expected to start at 2, found %d", v)
+	}
+	next := func(want int) {
+		t.Helper()
+		if v, ok := c.Next(); !ok || v != want {
+			t.Fatalf("expected %d, found %d, %v", want, v, ok)
+		}
+	}
+
+	// forward, skipping 3 to 5
+	if off, err := c.Seek(6, io.SeekStart); err != nil || off != 6 {
+		t.Fatalf("expected to seek to 6, found %d, %v", off, err)
+	}
+	next(6)
+	// back, re-reading 4 and on
+	if off, err := c.Seek(-3, io.SeekCurrent); err != nil || off != 4 {
+		t.Fatalf("expected to seek back to 4, found %d, %v", off, err)
+	}
+	next(4)
+	next(5)
+	if off, err := c.Seek(-1, io.SeekEnd); err != nil || off != 9 {
+		t.Fatalf("expected to seek to the last offset 9, found %d, %v", off, err)
+	}
+	next(9)
+
+	// an evicted offset leaves the cursor where it was
+	if _, err := c.Seek(1, io.SeekStart); !errors.Is(err, ErrOffsetEvicted) {
+		t.Fatalf("expected ErrOffsetEvicted, found %v", err)
+	}
+	if _, err := c.Seek(-20, io.SeekCurrent); err == nil {
+		t.Fatal("expected a negative offset to be rejected")
+	}
+	if c.Offset() != 10 {
+		t.Fatalf("expected the cursor to remain at 10, found %d", c.Offset())
+	}
+	if err := p.Append(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	next(10)
+}
+
+func TestCursor_OffsetPassesRemovedElements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewKeyedPool[string, string](ctx, Policy{Count: 10}, func(s string) string {
+		return s[:1]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// b2 replaces b1, leaving offset 1 empty
+	for _, s := range []string{"a1", "b1", "c1", "b2"} {
+		if err := p.Append(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := p.Cursor(ctx, 0)
+	for _, want := range []struct {
+		value  string
+		offset int64
+	}{{"a1", 1}, {"c1", 3}, {"b2", 4}} {
+		if v, ok := c.Next(); !ok || v != want.value || c.Offset() != want.offset {
+			t.Fatalf("expected %s, advancing to %d, found %s at %d", want.value, want.offset, v, c.Offset())
+		}
+	}
+}
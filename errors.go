@@ -0,0 +1,37 @@
+package pools
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOffsetEvicted is returned when a requested offset has already been removed from the pool by its Policy.
+var ErrOffsetEvicted = errors.New("offset evicted")
+
+// ErrUnconstrainedPolicy is returned when creating a pool with a Policy which does not limit its memory.
+var ErrUnconstrainedPolicy = errors.New("policy is unconstrained. Pool can not have unlimited memory")
+
+// ErrInvalidPolicy is returned when a Policy has an invalid setting.
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// ErrMaxAgeTooShort is returned when a Policy MaxAge is less than MinMaxAge.
+var ErrMaxAgeTooShort = errors.New("policy MaxAge too short")
+
+// ErrTooManyReaders is returned when a read would exceed the Policy MaxReaders.
+var ErrTooManyReaders = errors.New("too many readers")
+
+// ErrPoolClosed is returned when an operation fails as the pool has shutdown.
+var ErrPoolClosed = errors.New("pool has shutdown")
+
+// ErrRequestAborted is returned when a read request is ended before it completes.
+var ErrRequestAborted = errors.New("request aborted")
+
+// ErrComplete is sent on the error channel of a bounded read once it has delivered all it requires.
+// It marks the successful end of the read, as io.EOF does a stream.
+var ErrComplete = errors.New("read complete")
+
+// ErrAlreadyFed is returned when FeedOnce is given a channel which is already being fed into the pool.
+var ErrAlreadyFed = errors.New("channel is already being fed")
+
+// errAbortedByShutdown ends read requests when the pool shuts down.
+var errAbortedByShutdown = fmt.Errorf("%w as %w", ErrRequestAborted, ErrPoolClosed)
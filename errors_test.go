@@ -0,0 +1,56 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestErrPoolClosed_ShutdownPaths(t *testing.T) {
+	ctx := context.Background()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0)
+	p.Close()
+	p.WaitForClose()
+
+	tests := map[string]func() error{
+		"Append":    func() error { return p.Append(ctx, 1) },
+		"FeedSlice": func() error { return p.FeedSlice(ctx, []int{1}) },
+		"SetPolicy": func() error { return p.SetPolicy(Policy{Count: 5}) },
+		"Reset":     func() error { return p.Reset(ctx, 1) },
+		"WaitForData": func() error {
+			return p.WaitForData(ctx, 1)
+		},
+		"WaitForOffset": func() error {
+			return p.WaitForOffset(ctx, 1)
+		},
+		"Quiesce": func() error { return p.Quiesce(ctx) },
+		"Ping":    func() error { return p.Ping(ctx) },
+		"ReadWithErr": func() error {
+			r, errc := p.ReadWithErr(ctx, 0)
+			for range r {
+			}
+			return <-errc
+		},
+	}
+	for name, fn := range tests {
+		if err := fn(); !errors.Is(err, ErrPoolClosed) {
+			t.Errorf("%s: expected ErrPoolClosed, found %v", name, err)
+		}
+	}
+}
+
+func TestErrRequestAborted_ShutdownWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	// read with a context of its own, so the read is ended by the pool, not its context
+	r, errc := p.ReadWithErr(context.Background(), 0)
+	waitForWaitingRead[int](p)
+	cancel()
+	for range r {
+	}
+	err := <-errc
+	if !errors.Is(err, ErrPoolClosed) || !errors.Is(err, ErrRequestAborted) {
+		t.Fatalf("expected an aborted request of a closed pool, found %v", err)
+	}
+}
@@ -32,9 +32,12 @@ func main() {
 
 	pt := NewPoolTest(strings.Split("hello world, how you doing?\n", " ")...)
 
-	p := pools.NewPool[*PoolTest](ctx, pools.Policy{
+	p, err := pools.NewPool[*PoolTest](ctx, pools.Policy{
 		Count: 2,
 	}, pt...)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
 	ctxx, cnlx := context.WithCancel(ctx)
 	for i := 0; i < 500; i++ {
@@ -50,7 +53,7 @@ func main() {
 
 	//go feedPool(ctx, p, "console1")
 
-	sig := make(chan os.Signal)
+	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, os.Kill, os.Interrupt)
 	select {
 	case <-sig:
@@ -0,0 +1,13 @@
+package pools
+
+// GapEvent reports the offsets a gap tolerant reader missed, as they were evicted before it could read them.
+type GapEvent struct {
+	// MissedFrom is the first offset missed.
+	MissedFrom int64
+	// MissedTo is the offset following the last one missed, where reading continued.
+	MissedTo int64
+	// Reset is true when the gap was made by the pool being reset. The reader missed all the elements from MissedFrom
+	// in the old data, and continues from MissedTo, the first offset of the new data, so MissedTo may be below
+	// MissedFrom.
+	Reset bool
+}
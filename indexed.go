@@ -0,0 +1,9 @@
+package pools
+
+// Indexed is an element delivered by ReadIndexed, with the offset it is held at in the pool.
+type Indexed[T any] struct {
+	// Offset is the offset of the element, which a reader may record to resume reading from the following offset.
+	Offset int64
+	// Value is the element.
+	Value T
+}
@@ -0,0 +1,28 @@
+package pools
+
+import "context"
+
+// NewKeyedPool creates a new Pool which keeps only the latest element of each key, as given by keyFn.
+// As an element is fed, any earlier element with the same key is removed, so the pool holds, at most, one element
+// per distinct key, with the Policy Count limiting the number of keys held.
+// keyFn is called on the pool thread as each element is fed or removed, so must not call the methods of the pool,
+// such as Len, Stats or Read, which would deadlock.
+// Readers receive the elements in the order they were fed, a removed element leaving a gap in the offsets, which
+// readers pass over. Should keyFn panic, the panic is logged and the element held without a key, replacing no other.
+// As the gaps are not kept in a snapshot, offsets in a reloaded keyed pool may differ.
+// Any options are applied as with NewPoolWithOptions. An error is returned if the policy is not valid or
+// its Overflow is not OverflowDropOldest.
+func NewKeyedPool[K comparable, T any](ctx context.Context, policy Policy, keyFn func(T) K, opts ...Option[T]) (Pool[T], error) {
+	opts = append(opts, withKey(keyFn))
+	return NewPoolWithOptions(ctx, policy, opts...)
+}
+
+// withKey makes a pool keyed, keeping only the latest element of each key.
+func withKey[K comparable, T any](keyFn func(T) K) Option[T] {
+	return func(p *pool[T]) {
+		p.keyOf = func(t T) any {
+			return keyFn(t)
+		}
+		p.keys = map[any]int64{}
+	}
+}
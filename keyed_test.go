@@ -0,0 +1,66 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type keyedValue struct {
+	key   string
+	value int
+}
+
+func TestNewKeyedPool_LatestPerKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keyOf := func(kv keyedValue) string { return kv.key }
+	p, err := NewKeyedPool(ctx, Policy{Count: 3}, keyOf,
+		WithData(keyedValue{"a", 1}, keyedValue{"b", 1}, keyedValue{"a", 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Snapshot(); len(s) != 2 || s[0] != (keyedValue{"b", 1}) || s[1] != (keyedValue{"a", 2}) {
+		t.Fatalf("expected the initial data compacted to [b:1 a:2], found %v", s)
+	}
+	r := p.Read(ctx, -1)
+	for _, want := range []keyedValue{{"b", 1}, {"a", 2}} {
+		if v := <-r; v != want {
+			t.Fatalf("expected %v, found %v", want, v)
+		}
+	}
+
+	for _, kv := range []keyedValue{{"b", 2}, {"c", 1}, {"d", 1}} {
+		if err := p.Append(ctx, kv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []keyedValue{{"b", 2}, {"c", 1}, {"d", 1}} {
+		select {
+		case v := <-r:
+			if v != want {
+				t.Fatalf("expected %v, found %v", want, v)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %v to be read", want)
+		}
+	}
+	// the fourth key takes the pool over its Count, so the oldest, a, is evicted
+	if s := p.Snapshot(); len(s) != 3 || s[0] != (keyedValue{"b", 2}) || s[2] != (keyedValue{"d", 1}) {
+		t.Fatalf("expected [b:2 c:1 d:1], found %v", s)
+	}
+	// a:1 and b:1 were replaced, a:2 evicted by the Count
+	if stats := p.Stats(); stats.Length != 3 || stats.Evicted != 3 {
+		t.Fatalf("expected 3 elements held and 3 evicted, found %+v", stats)
+	}
+}
+
+func TestNewKeyedPool_RejectsBlockingPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := NewKeyedPool(ctx, Policy{Count: 3, Overflow: OverflowBlock}, func(kv keyedValue) string { return kv.key })
+	if !errors.Is(err, ErrInvalidPolicy) {
+		t.Fatalf("expected ErrInvalidPolicy, found %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package pools
+
+// Logger receives the log messages of a pool.
+// A *log.Logger may be used as a Logger.
+type Logger interface {
+	Println(v ...any)
+	Printf(format string, v ...any)
+}
+
+// nopLogger is the default Logger, discarding all messages.
+type nopLogger struct{}
+
+func (nopLogger) Println(v ...any) {}
+
+func (nopLogger) Printf(format string, v ...any) {}
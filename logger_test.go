@@ -0,0 +1,65 @@
+package pools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureLogger records each message logged.
+type captureLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *captureLogger) Println(v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+func (l *captureLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+}
+
+func (l *captureLogger) contains(s string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, m := range l.messages {
+		if strings.Contains(m, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &captureLogger{}
+	p, err := NewPoolWithOptions[int](context.Background(), Policy{Count: 3}, WithData(0, 1), WithLogger[int](logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+	p.WaitForClose()
+	if !logger.contains("pool is starting") {
+		t.Fatalf("expected the pool to log its start, found %q", logger.messages)
+	}
+	if !logger.contains("shutting down with 2 elements") {
+		t.Fatalf("expected the pool to log its shutdown, found %q", logger.messages)
+	}
+}
+
+func TestNopLogger_IsDefault(t *testing.T) {
+	p, err := NewPoolWithOptions[int](context.Background(), Policy{Count: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.(*pool[int]).logger.(nopLogger); !ok {
+		t.Fatalf("expected the default logger to discard messages, found %T", p.(*pool[int]).logger)
+	}
+	p.Close()
+}
@@ -1,90 +1,433 @@
 package pools
 
 import (
+	"reflect"
+	"sort"
+	"time"
 	"unsafe"
 )
 
+// offsetData holds the elements of a pool in a ring buffer, indexed by their absolute offset.
+// Capacity is reused as elements are trimmed, and trimmed slots are zeroed so the elements they held may be collected.
+// offsetData is not safe for concurrent use. Each pool's data is owned by its pool thread, runPool, and is only ever
+// reached by other goroutines through a command or request, run on that thread.
 type offsetData[T any] struct {
-	data   []T
-	offset int
+	data     []T         // ring buffer of the elements
+	times    []time.Time // insertion time of each element in data
+	head     int         // index in data of the first element
+	length   int         // number of elements held in data
+	offset   int64
+	consumed int64            // offset below which all elements have been read
+	size     uint64           // running total of the byte size of the elements held
+	now      func() time.Time // the clock giving the insertion time of appended elements
+	sizer    func(T) uint64
+	onEvict  func(T)                 // called with each element as it is removed
+	onUnread func(T)                 // when not nil, called with each element removed before it was read
+	onRemove func(offset int64, t T) // when not nil, called with each element as it is removed, with its offset
+	removed  map[int64]struct{}      // offsets of elements removed from within the data, leaving an empty slot
 }
 
-// Length returns the number of elements in the data
+func newOffsetData[T any](offset int64, sizer func(T) uint64, onEvict func(T), data ...T) *offsetData[T] {
+	now := time.Now()
+	times := make([]time.Time, len(data))
+	for i := range times {
+		times[i] = now
+	}
+	buf := make([]T, len(data))
+	copy(buf, data)
+	d := &offsetData[T]{
+		data:    buf,
+		times:   times,
+		length:  len(buf),
+		offset:  offset,
+		now:     time.Now,
+		sizer:   sizer,
+		onEvict: onEvict,
+	}
+	for _, t := range buf {
+		d.size += d.sizeOf(t)
+	}
+	return d
+}
+
+// Length returns the number of elements in the data, including any empty slots left by Remove.
 func (d offsetData[T]) Length() int {
-	return len(d.data)
+	return d.length
+}
+
+// LiveLength returns the number of elements in the data, excluding any empty slots left by Remove.
+func (d offsetData[T]) LiveLength() int {
+	return d.length - len(d.removed)
 }
 
 // LengthFrom returns the number of elements in the data following (and including) the element at the given offset
 // If given offset is less than the current pool offset, or greater than the pool offset plus its Length, zero is returned.
-func (d offsetData[T]) LengthFrom(offset int) int {
+func (d offsetData[T]) LengthFrom(offset int64) int {
 	i := d.IndexOf(offset)
 	if i < 0 { // offset out of range
 		return 0
 	}
-	return len(d.data) - i
+	return d.length - i
 }
 
 // Size returns the byte size of the data
 func (d offsetData[T]) Size() uint64 {
-	l := len(d.data)
-	if l == 0 {
-		return 0
-	}
-	sz := d.elementSize()
-	return sz * uint64(l)
+	return d.size
 }
 
-func (d offsetData[T]) Offset() int {
+func (d offsetData[T]) Offset() int64 {
 	return d.offset
 }
 
+// NextOffset returns the offset of the next element to be appended.
+func (d offsetData[T]) NextOffset() int64 {
+	return d.offset + int64(d.length)
+}
+
 func (d *offsetData[T]) Append(t ...T) {
-	d.data = append(d.data, t...)
+	now := d.now()
+	for _, e := range t {
+		d.appendAt(e, d.sizeOf(e), now)
+	}
 }
 
-func (d *offsetData[T]) SliceFrom(offset int) []T {
+// AppendSized appends the element, already measured as the given byte size, so it is not measured again.
+func (d *offsetData[T]) AppendSized(t T, size uint64) {
+	d.appendAt(t, size, d.now())
+}
+
+// appendAt appends the element, of the given byte size, inserted at the given time.
+func (d *offsetData[T]) appendAt(t T, size uint64, now time.Time) {
+	if d.length == len(d.data) {
+		d.grow()
+	}
+	i := d.slot(d.length)
+	d.data[i] = t
+	d.times[i] = now
+	d.length++
+	d.size += size
+}
+
+// OldestTime returns the insertion time of the first element in the data.
+// false is returned if the data is empty.
+func (d offsetData[T]) OldestTime() (time.Time, bool) {
+	if d.length == 0 {
+		return time.Time{}, false
+	}
+	return d.times[d.head], true
+}
+
+// OffsetSince returns the offset of the first element inserted at or after the given time.
+// If no element was inserted since the time, the next offset is returned.
+func (d offsetData[T]) OffsetSince(since time.Time) int64 {
+	// insertion times only increase, so the first element not before the time is searched for
+	i := sort.Search(d.length, func(i int) bool {
+		return !d.times[d.slot(i)].Before(since)
+	})
+	return d.offset + int64(i)
+}
+
+// MarkConsumed marks the elements from the given offset, up to but not including the given end, as having been read.
+// Elements are only consumed up to the first which has not been read, so a range starting beyond those already
+// consumed leaves them, and itself, unread, until the elements before it are also marked.
+func (d *offsetData[T]) MarkConsumed(from, end int64) {
+	consumed := d.consumed
+	if consumed < d.offset {
+		// elements already removed need not be read
+		consumed = d.offset
+	}
+	if from <= consumed && end > consumed {
+		d.consumed = end
+	}
+}
+
+// ConsumedLength returns the number of elements in the data which have been marked as read.
+func (d offsetData[T]) ConsumedLength() int {
+	l := d.consumed - d.offset
+	if l < 0 {
+		return 0
+	}
+	if l > int64(d.length) {
+		return d.length
+	}
+	return int(l)
+}
+
+// SliceFrom returns a copy of the elements from the given offset to the end of the data.
+// If the offset is out of range, all the elements are returned.
+func (d *offsetData[T]) SliceFrom(offset int64) []T {
 	i := d.IndexOf(offset)
 	if i < 0 {
 		i = 0
 	}
-	return d.data[i:]
+	s := make([]T, d.length-i)
+	for j := range s {
+		s[j] = d.at(i + j)
+	}
+	return s
+}
+
+// LiveFrom returns a copy of the elements from the given offset to the end of the data, excluding any empty slots.
+// If the offset is out of range, all the elements are returned.
+func (d *offsetData[T]) LiveFrom(offset int64) []T {
+	s := d.SliceFrom(offset)
+	if len(d.removed) == 0 {
+		return s
+	}
+	live := s[:0]
+	for j, t := range s {
+		if !d.IsRemoved(d.NextOffset() - int64(len(s)-j)) {
+			live = append(live, t)
+		}
+	}
+	return live
+}
+
+// RemovedFrom returns a flag for each of the n elements from the given offset, true where the slot is empty.
+// nil is returned when none of the slots are empty.
+func (d offsetData[T]) RemovedFrom(offset int64, n int) []bool {
+	if len(d.removed) == 0 {
+		return nil
+	}
+	var flags []bool
+	for j := 0; j < n; j++ {
+		if d.IsRemoved(offset + int64(j)) {
+			if flags == nil {
+				flags = make([]bool, n)
+			}
+			flags[j] = true
+		}
+	}
+	return flags
+}
+
+// Get returns the element at the given offset, false if the offset is out of range or its element has been removed.
+func (d offsetData[T]) Get(offset int64) (T, bool) {
+	i := d.IndexOf(offset)
+	if i < 0 || d.IsRemoved(offset) {
+		var zero T
+		return zero, false
+	}
+	return d.at(i), true
+}
+
+// IsRemoved checks if the element at the given offset has been removed by Remove, leaving an empty slot.
+func (d offsetData[T]) IsRemoved(offset int64) bool {
+	_, ok := d.removed[offset]
+	return ok
+}
+
+// Remove removes the element at the given offset from within the data, leaving an empty slot, so the offsets of the
+// following elements are unchanged. Any empty slots left at the start of the data are trimmed.
+func (d *offsetData[T]) Remove(offset int64) {
+	i := d.IndexOf(offset)
+	if i < 0 || d.IsRemoved(offset) {
+		return
+	}
+	var zero T
+	s := d.slot(i)
+	d.shrink(d.sizeOf(d.data[s]))
+	d.evict(offset, d.data[s])
+	d.data[s] = zero
+	if d.removed == nil {
+		d.removed = map[int64]struct{}{}
+	}
+	d.removed[offset] = struct{}{}
+	d.trimRemoved()
 }
 
-func (d *offsetData[T]) IndexOf(offset int) int {
+func (d *offsetData[T]) IndexOf(offset int64) int {
 	if offset < d.offset {
 		return -1
 	}
 	i := offset - d.offset
-	if i >= len(d.data) {
+	if i >= int64(d.length) {
 		return -1
 	}
-	return i
+	return int(i)
+}
+
+// Reset removes all the elements, restarting the offsets at the given offset.
+func (d *offsetData[T]) Reset(offset int64) {
+	d.TrimToLength(0)
+	d.offset = offset
+	d.consumed = offset
+	d.removed = nil
 }
 
+// TrimToLength removes the oldest elements, leaving, at most, the given count of the most recent.
+// The removed slots are zeroed, so evicted pointers, slices and maps are no longer referenced by the pool.
 func (d *offsetData[T]) TrimToLength(count int) {
-	if count >= len(d.data) {
+	if count >= d.length {
+		return
+	}
+	if count < 0 {
+		count = 0
+	}
+	cut := d.length - count
+	var zero T
+	for i := 0; i < cut; i++ {
+		if off := d.offset + int64(i); d.IsRemoved(off) {
+			delete(d.removed, off)
+		} else {
+			d.shrink(d.sizeOf(d.data[d.head]))
+			d.evict(off, d.data[d.head])
+		}
+		d.data[d.head] = zero
+		d.times[d.head] = time.Time{}
+		d.head = d.slot(1)
+	}
+	d.length = count
+	d.offset += int64(cut)
+	d.trimRemoved()
+}
+
+// TrimToLive removes the oldest elements, leaving, at most, the given count of the most recent, not counting any
+// empty slots left by Remove.
+func (d *offsetData[T]) TrimToLive(count int) {
+	cut := 0
+	for live := d.LiveLength(); cut < d.length && live > count; cut++ {
+		if !d.IsRemoved(d.offset + int64(cut)) {
+			live--
+		}
+	}
+	d.TrimToLength(d.length - cut)
+}
+
+// TruncateToLength removes the most recent elements, leaving, at most, the given count of the oldest.
+// As with TrimToLength, the removed slots are zeroed.
+func (d *offsetData[T]) TruncateToLength(count int) {
+	if count >= d.length {
 		return
 	}
 	if count < 0 {
 		count = 0
 	}
-	cut := len(d.data) - count
-	d.offset += cut
-	d.data = d.data[cut:]
+	var zero T
+	for i := count; i < d.length; i++ {
+		if off := d.offset + int64(i); d.IsRemoved(off) {
+			delete(d.removed, off)
+		} else {
+			d.shrink(d.sizeOf(d.data[d.slot(i)]))
+			d.evict(off, d.data[d.slot(i)])
+		}
+		d.data[d.slot(i)] = zero
+		d.times[d.slot(i)] = time.Time{}
+	}
+	d.length = count
+}
+
+// TrimToAge removes all the elements which were inserted before the given time.
+func (d *offsetData[T]) TrimToAge(before time.Time) {
+	cut := 0
+	for cut < d.length && d.times[d.slot(cut)].Before(before) {
+		cut++
+	}
+	d.TrimToLength(d.length - cut)
 }
 
+// TrimToSize removes the oldest elements until the byte size of the data is no greater than the given size.
+// Elements are measured one at a time, so elements of varying sizes never leave the data over the given size.
 func (d *offsetData[T]) TrimToSize(size uint64) {
-	dsize := d.Size()
-	esize := d.elementSize()
-	if dsize == 0 || esize == 0 || size >= dsize {
+	cut := 0
+	total := d.size
+	for cut < d.length && total > size {
+		if d.IsRemoved(d.offset + int64(cut)) {
+			cut++
+			continue
+		}
+		if es := d.sizeOf(d.at(cut)); es < total {
+			total -= es
+		} else {
+			total = 0
+		}
+		cut++
+	}
+	d.TrimToLength(d.length - cut)
+}
+
+// sizeOf returns the byte size of the given element, measured by the sizer, if set, or the element's own Sizer,
+// or for a byte slice, its length, otherwise the memory size of the element type.
+func (d offsetData[T]) sizeOf(t T) uint64 {
+	if d.sizer != nil {
+		return d.sizer(t)
+	}
+	return elementSize(t)
+}
+
+// elementSize measures an element by its own Sizer, by length for a byte slice, or otherwise by its type.
+// Byte slices include named types of byte slice, such as json.RawMessage.
+func elementSize[T any](t T) uint64 {
+	switch v := any(t).(type) {
+	case Sizer:
+		return v.PoolSize()
+	case []byte:
+		return uint64(len(v))
+	}
+	if rt := reflect.TypeOf(t); rt != nil && rt.Kind() == reflect.Slice && rt.Elem().Kind() == reflect.Uint8 {
+		return uint64(reflect.ValueOf(t).Len())
+	}
+	return uint64(unsafe.Sizeof(t))
+}
+
+// shrink reduces the running size by the given size of a removed element.
+// The size is floored at zero, should a sizer measure an element differently to when it was added.
+func (d *offsetData[T]) shrink(size uint64) {
+	if size > d.size {
+		d.size = 0
 		return
 	}
-	d.TrimToLength(int(size / esize))
+	d.size -= size
 }
 
-func (d offsetData[T]) elementSize() uint64 {
-	if len(d.data) == 0 {
-		return 0
+// evict passes the element, removed from the given offset, to the eviction callbacks.
+// An element is unread when at or beyond consumed, which only advances past elements every reader has read.
+func (d offsetData[T]) evict(offset int64, t T) {
+	if d.onUnread != nil && offset >= d.consumed {
+		d.onUnread(t)
+	}
+	if d.onEvict != nil {
+		d.onEvict(t)
+	}
+	if d.onRemove != nil {
+		d.onRemove(offset, t)
+	}
+}
+
+// trimRemoved trims any empty slots from the start of the data, so the data always starts with an element.
+func (d *offsetData[T]) trimRemoved() {
+	cut := 0
+	for cut < d.length && d.IsRemoved(d.offset+int64(cut)) {
+		cut++
+	}
+	if cut > 0 {
+		d.TrimToLength(d.length - cut)
+	}
+}
+
+// at returns the element at the given index, relative to the first element.
+func (d offsetData[T]) at(i int) T {
+	return d.data[d.slot(i)]
+}
+
+// slot returns the position in the ring buffer of the given index, relative to the first element.
+func (d offsetData[T]) slot(i int) int {
+	return (d.head + i) % len(d.data)
+}
+
+// grow increases the capacity of the ring buffer, unwrapping the elements to start at the beginning of the new buffer.
+func (d *offsetData[T]) grow() {
+	c := len(d.data) * 2
+	if c == 0 {
+		c = 8
+	}
+	data := make([]T, c)
+	times := make([]time.Time, c)
+	for i := 0; i < d.length; i++ {
+		data[i] = d.data[d.slot(i)]
+		times[i] = d.times[d.slot(i)]
 	}
-	return uint64(unsafe.Sizeof(d.data[0]))
+	d.data = data
+	d.times = times
+	d.head = 0
 }
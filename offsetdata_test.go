@@ -0,0 +1,185 @@
+package pools
+
+import (
+	"math"
+	"runtime"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestOffsetData_TrimToLength(t *testing.T) {
+	d := newOffsetData[int](0, nil, nil)
+	for i := 0; i < 1000; i++ {
+		d.Append(i)
+		d.TrimToLength(10)
+	}
+	if d.Length() != 10 {
+		t.Fatalf("expected length 10, found %d", d.Length())
+	}
+	if d.Offset() != 990 {
+		t.Fatalf("expected offset 990, found %d", d.Offset())
+	}
+	if v := d.SliceFrom(990)[0]; v != 990 {
+		t.Fatalf("expected first element 990, found %d", v)
+	}
+}
+
+type poolTest struct {
+	Name string
+}
+
+func TestOffsetData_TrimToSizeWithSizer(t *testing.T) {
+	sizer := func(pt *poolTest) uint64 { return uint64(len(pt.Name)) }
+	d := newOffsetData(0, sizer, nil, &poolTest{"aaaa"}, &poolTest{"bb"}, &poolTest{"cccc"}, &poolTest{"d"})
+	if d.Size() != 11 {
+		t.Fatalf("expected size 11, found %d", d.Size())
+	}
+	d.TrimToSize(6)
+	if d.Size() != 5 || d.Offset() != 2 {
+		t.Fatalf("expected size 5 from offset 2, found %d from offset %d", d.Size(), d.Offset())
+	}
+}
+
+func TestOffsetData_SizeWithoutSizer(t *testing.T) {
+	d := newOffsetData[*poolTest](0, nil, nil, &poolTest{"a long name, measured by the pointer alone"})
+	if want := uint64(unsafe.Sizeof(&poolTest{})); d.Size() != want {
+		t.Fatalf("expected the pointer size %d, found %d", want, d.Size())
+	}
+}
+
+func TestOffsetData_TrimToAge(t *testing.T) {
+	clock := newFakeClock()
+	d := newOffsetData[int](0, nil, nil)
+	d.now = clock.Now
+	d.Append(0, 1)
+	clock.Advance(time.Minute)
+	d.Append(2)
+	d.TrimToAge(clock.Now().Add(-30 * time.Second))
+	if d.Length() != 1 || d.Offset() != 2 {
+		t.Fatalf("expected only offset 2 to remain, found %d from offset %d", d.Length(), d.Offset())
+	}
+}
+
+func TestOffsetData_RingKeepsOffsets(t *testing.T) {
+	d := newOffsetData[int](0, nil, nil)
+	// appending and trimming in turn wraps the ring many times over
+	for i := 0; i < 100; i++ {
+		d.Append(i)
+		d.TrimToLength(7)
+	}
+	for offset := int64(93); offset < 100; offset++ {
+		if v := d.SliceFrom(offset)[0]; v != int(offset) {
+			t.Fatalf("expected %d at offset %d, found %d", offset, offset, v)
+		}
+		if i := d.IndexOf(offset); i != int(offset-93) {
+			t.Fatalf("expected index %d for offset %d, found %d", offset-93, offset, i)
+		}
+	}
+	if i := d.IndexOf(92); i >= 0 {
+		t.Fatalf("expected offset 92 to be evicted, found at index %d", i)
+	}
+	if s := d.SliceFrom(95); len(s) != 5 || s[0] != 95 {
+		t.Fatalf("expected [95 96 97 98 99], found %v", s)
+	}
+}
+
+func TestOffsetData_EvictedElementsAreCollected(t *testing.T) {
+	collected := make(chan struct{}, 10)
+	d := newOffsetData[*poolTest](0, nil, nil)
+	for i := 0; i < 10; i++ {
+		pt := &poolTest{Name: "evicted"}
+		runtime.SetFinalizer(pt, func(*poolTest) {
+			collected <- struct{}{}
+		})
+		d.Append(pt)
+	}
+	d.TrimToLength(0)
+	d.Append(&poolTest{Name: "held"})
+
+	timeout := time.After(5 * time.Second)
+	for n := 0; n < 10; {
+		runtime.GC()
+		select {
+		case <-collected:
+			n++
+		case <-timeout:
+			t.Fatalf("expected all 10 evicted elements to be collected, found %d", n)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	runtime.KeepAlive(d)
+}
+
+func BenchmarkOffsetData_AppendTrim(b *testing.B) {
+	d := newOffsetData[*poolTest](0, nil, nil)
+	pt := &poolTest{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.Append(pt)
+		d.TrimToLength(100)
+	}
+}
+
+func TestOffsetData_RunningSize(t *testing.T) {
+	sizer := func(s string) uint64 { return uint64(len(s)) }
+	d := newOffsetData[string](0, sizer, nil)
+	for _, s := range []string{"a", "bbbbbbbbbb", "ccc", "dddddd"} {
+		d.Append(s)
+	}
+	if d.Size() != 20 {
+		t.Fatalf("expected size 20, found %d", d.Size())
+	}
+	d.TrimToLength(2)
+	if d.Size() != 9 {
+		t.Fatalf("expected size 9 after trimming, found %d", d.Size())
+	}
+	d.TrimToSize(8)
+	if d.Size() != 6 || d.Offset() != 3 {
+		t.Fatalf("expected size 6 from offset 3, found %d from offset %d", d.Size(), d.Offset())
+	}
+	d.TrimToSize(6)
+	if d.Size() != 6 || d.Length() != 1 {
+		t.Fatalf("expected an element of exactly the budget to be kept, found size %d", d.Size())
+	}
+}
+
+func TestOffsetData_TrimToSizeMixedSizes(t *testing.T) {
+	sizer := func(s string) uint64 { return uint64(len(s)) }
+	sizes := []int{7, 1, 12, 3, 3, 9, 1, 5, 2, 8}
+	for budget := uint64(1); budget <= 30; budget++ {
+		d := newOffsetData[string](0, sizer, nil)
+		for _, n := range sizes {
+			d.Append(string(make([]byte, n)))
+			d.TrimToSize(budget)
+			var total uint64
+			for _, s := range d.SliceFrom(d.Offset()) {
+				total += uint64(len(s))
+			}
+			if total > budget || d.Size() != total {
+				t.Fatalf("budget %d: expected no more than the budget held, found %d, measured as %d", budget, total, d.Size())
+			}
+		}
+	}
+}
+
+func TestOffsetData_HugeBaseOffset(t *testing.T) {
+	base := int64(math.MaxInt64 - 10)
+	d := newOffsetData[int](base, nil, nil, 0, 1, 2, 3, 4)
+	if i := d.IndexOf(base + 3); i != 3 {
+		t.Fatalf("expected index 3, found %d", i)
+	}
+	if i := d.IndexOf(base - 1); i != -1 {
+		t.Fatalf("expected an offset before the base to be missing, found index %d", i)
+	}
+	if s := d.SliceFrom(base + 4); len(s) != 1 || s[0] != 4 {
+		t.Fatalf("expected [4] at the last offset, found %v", s)
+	}
+	d.TrimToLength(2)
+	if d.Offset() != base+3 || d.NextOffset() != base+5 {
+		t.Fatalf("expected offsets %d to %d, found %d to %d", base+3, base+5, d.Offset(), d.NextOffset())
+	}
+	if s := d.SliceFrom(base + 4); len(s) != 1 || s[0] != 4 {
+		t.Fatalf("expected [4], found %v", s)
+	}
+}
@@ -0,0 +1,133 @@
+package pools
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures an optional setting of a Pool as it is created.
+type Option[T any] func(p *pool[T])
+
+// WithData sets the initial data the Pool contains.
+// The data is copied as the Pool is created, so later changes to the given slice do not affect the Pool.
+// The data is held before the Pool starts, ahead of any element fed, so a Read from -1 starts with it,
+// while a Read from ReadLatest passes over it.
+func WithData[T any](data ...T) Option[T] {
+	return func(p *pool[T]) {
+		p.data = data
+	}
+}
+
+// WithInitialOffset sets the offset of the first element of the initial data, in place of zero, so a pool resumed from
+// an earlier pool may keep the same offsets. A negative offset is treated as zero.
+func WithInitialOffset[T any](offset int64) Option[T] {
+	return func(p *pool[T]) {
+		if offset < 0 {
+			offset = 0
+		}
+		p.offset = offset
+	}
+}
+
+// WithSizer sets the function used to measure the byte size of each element, when applying the Policy Size.
+// Without a sizer, the memory size of the element type is used, which, for pointers, slices, maps etc.
+// only measures the reference and not the data it refers to, unless the element type implements Sizer.
+// Byte slices, including named byte slice types, are the exception, measured by their length.
+// The sizer is called on the pool thread, so must not call the methods of the pool, which would deadlock.
+// Should the sizer panic, the panic is logged and the element measured as zero.
+func WithSizer[T any](sizer func(T) uint64) Option[T] {
+	return func(p *pool[T]) {
+		p.sizer = sizer
+	}
+}
+
+// WithLogger sets the Logger the pool writes its log messages to.
+// By default, a pool does not log.
+func WithLogger[T any](logger Logger) Option[T] {
+	return func(p *pool[T]) {
+		p.logger = logger
+	}
+}
+
+// WithOnEvict sets a function called with each element as it is removed from the pool,
+// either by the Policy or when the pool shuts down.
+// The function is called on the pool thread, so it should be fast, passing any lengthy work on to another goroutine,
+// and must not call the methods of the pool, such as Len, Stats or Read, which would deadlock waiting on the thread.
+// It should not panic, though should it do so, the panic is recovered and logged and the pool continues.
+func WithOnEvict[T any](onEvict func(T)) Option[T] {
+	return func(p *pool[T]) {
+		p.onEvict = onEvict
+	}
+}
+
+// WithRequestBuffer sets the size of the queues of read requests waiting to be serviced by the pool.
+// Every active reader resubmits its request after each delivery, so a pool with many readers may benefit from
+// a larger buffer, reducing the time readers block when resubmitting. A larger buffer costs memory and
+// allows more requests to wait behind a feed.
+func WithRequestBuffer[T any](size int) Option[T] {
+	return func(p *pool[T]) {
+		if size < 0 {
+			size = 0
+		}
+		p.requests = make(chan request[T], size)
+		p.priorityRequests = make(chan request[T], size)
+	}
+}
+
+// WithFeedBuffer sets the size of the queue of elements waiting to be added to the pool.
+// By default, feeds are unbuffered, so each element is handed directly to the pool and a feeder waits while the pool
+// services its readers. A buffer decouples feeders from the pool, at the cost of an accepted element, including
+// one passed to Append, only being queued and not yet held in the pool. Queued elements are added in the order they
+// were accepted, and any still queued when the pool shuts down are discarded.
+func WithFeedBuffer[T any](size int) Option[T] {
+	return func(p *pool[T]) {
+		if size < 0 {
+			size = 0
+		}
+		p.feed = make(chan T, size)
+	}
+}
+
+// WithOverflowSink sets a channel to receive each element the Policy removes from the pool before any reader has read it,
+// including elements the Policy rejects as they are fed. Elements removed when the pool is flushed or shuts down are not sent.
+// The pool never waits on the sink: an element the sink can not take immediately is dropped, and counted in
+// PoolStats.SinkDropped, so the sink should be buffered to hold the elements arriving between receives.
+func WithOverflowSink[T any](sink chan<- T) Option[T] {
+	return func(p *pool[T]) {
+		p.overflowSink = sink
+	}
+}
+
+// WithSkipZero drops any element fed into the pool which is the zero value of its type, such as a nil pointer,
+// so readers never receive them. Dropped elements are not counted as fed. Any initial data is kept as given.
+func WithSkipZero[T any]() Option[T] {
+	return func(p *pool[T]) {
+		p.skipZero = true
+	}
+}
+
+// WithOnDeliver sets a function called with each element as it is delivered to a reader, along with the context of
+// the read, so values the reader's context carries, such as a tracing span, may be recorded against the delivery.
+// The function is called on the goroutine of the reader, once the element has been received, so delays that reader
+// alone. Any panic in the function is recovered and logged.
+func WithOnDeliver[T any](onDeliver func(ctx context.Context, t T)) Option[T] {
+	return func(p *pool[T]) {
+		p.onDeliver = onDeliver
+	}
+}
+
+// WithOnClose sets a function called once the pool has shutdown, with its final stats.
+// It is called whether the pool is closed or its context cancelled, once the pool is done, so WaitForClose may return
+// before it has been called. Any panic in the function is recovered and logged.
+func WithOnClose[T any](onClose func(final PoolStats)) Option[T] {
+	return func(p *pool[T]) {
+		p.onClose = onClose
+	}
+}
+
+// withClock sets the clock the pool reads the time from, in place of time.Now.
+func withClock[T any](now func() time.Time) Option[T] {
+	return func(p *pool[T]) {
+		p.now = now
+	}
+}
@@ -0,0 +1,434 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithOverflowSink_UnreadSinkDoesNotBlockCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := make(chan int)
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 2}, WithOverflowSink[int](sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a reader holds the oldest element unread, so the third feed is sent to the sink
+	_ = p.Read(ctx, 0)
+	for i := 0; i < 3; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the unbuffered sink is never received from, so the third element is dropped rather than stalling the pool
+	cancel()
+
+	wctx, wcancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer wcancel()
+	if err := p.WaitForCloseContext(wctx); err != nil {
+		t.Fatalf("pool did not close after cancel with an unread sink: %v", err)
+	}
+}
+
+func TestWithOnEvict_TrimAndShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var evicted []int
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 3}, WithData(0, 1),
+		WithOnEvict(func(i int) {
+			evicted = append(evicted, i)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := make(chan int, 4)
+	for i := 2; i < 6; i++ {
+		feed <- i
+	}
+	p.Feed(ctx, feed)
+	waitForFed(t, p, 6)
+	// the callback runs on the pool thread, so is only read once the pool has shutdown
+	p.Close()
+	p.WaitForClose()
+	if len(evicted) != 6 {
+		t.Fatalf("expected all 6 elements to be evicted, found %v", evicted)
+	}
+	for i, v := range evicted {
+		if v != i {
+			t.Fatalf("expected the elements evicted in order, found %v", evicted)
+		}
+	}
+}
+
+func TestWithFeedBuffer_KeepsOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 100}, WithFeedBuffer[int](16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.WaitForOffset(ctx, 99); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range p.Snapshot() {
+		if v != i {
+			t.Fatalf("expected %d at offset %d, found %d", i, i, v)
+		}
+	}
+}
+
+func benchmarkFeedUnderReadLoad(b *testing.B, opts ...Option[int]) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 1000}, opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		go func(r <-chan int) {
+			for range r {
+			}
+		}(p.Read(ctx, ReadLatest))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFeed_Unbuffered(b *testing.B) {
+	benchmarkFeedUnderReadLoad(b)
+}
+
+func BenchmarkFeed_Buffered(b *testing.B) {
+	benchmarkFeedUnderReadLoad(b, WithFeedBuffer[int](256))
+}
+
+func TestWithOverflowSink_ReceivesUnreadEvictions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := make(chan int, 100)
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 3}, WithOverflowSink[int](sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reader holds its first element until every element is fed, so the rest are evicted before it reads them
+	seen := make(map[int]bool)
+	read := make(chan struct{})
+	fed := make(chan struct{})
+	r := p.ReadFrom(ctx, FromEarliestFollow)
+	go func() {
+		defer close(read)
+		for v := range r {
+			seen[v] = true
+			if v == 99 {
+				return
+			}
+			<-fed
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(fed)
+	<-read
+
+	last := -1
+	for len(sink) > 0 {
+		v := <-sink
+		if v <= last {
+			t.Fatalf("expected sink elements in order, found %d after %d", v, last)
+		}
+		last = v
+		seen[v] = true
+	}
+	if last < 0 {
+		t.Fatal("expected evicted elements on the sink")
+	}
+	for i := 0; i < 100; i++ {
+		if !seen[i] {
+			t.Fatalf("element %d was neither read nor sent to the sink", i)
+		}
+	}
+}
+
+func TestWithOverflowSink_ReaderStartingMidPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := make(chan int, 10)
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 5}, WithOverflowSink[int](sink), WithData(1, 2, 3, 4, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := p.Read(ctx, -3)
+	for _, want := range []int{4, 5} {
+		if v := <-r; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+	for lags := p.ReaderLags(); lags[0] != 0; lags = p.ReaderLags() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the elements ahead of where the reader started were never read, so are sent to the sink as they are evicted
+	for _, v := range []int{6, 7} {
+		if err := p.Append(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []int{1, 2} {
+		select {
+		case v := <-sink:
+			if v != want {
+				t.Fatalf("expected %d on the sink, found %d", want, v)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d on the sink, found nothing", want)
+		}
+	}
+}
+
+func TestWithOverflowSink_FullSinkDropsElements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := make(chan int, 1)
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 2}, WithOverflowSink[int](sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a reader holds the oldest element unread, so each feed beyond the Count evicts an unread element
+	_ = p.Read(ctx, 0)
+	for i := 0; i < 5; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the pool keeps serving while the sink is full
+	if v := <-p.Read(ctx, -1); v != 3 {
+		t.Fatalf("expected 3, found %d", v)
+	}
+	if v := <-sink; v != 0 {
+		t.Fatalf("expected 0 on the sink, found %d", v)
+	}
+	if stats := p.Stats(); stats.SinkDropped != 2 {
+		t.Fatalf("expected 2 elements dropped from the full sink, found %d", stats.SinkDropped)
+	}
+}
+
+func TestWithSkipZero(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[*poolTest](ctx, Policy{Count: 10}, WithSkipZero[*poolTest]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan *poolTest)
+	p.Feed(ctx, ch)
+	for _, pt := range []*poolTest{nil, {Name: "one"}, nil, nil, {Name: "two"}, nil} {
+		ch <- pt
+	}
+	if err := p.Append(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(ctx, &poolTest{Name: "three"}); err != nil {
+		t.Fatal(err)
+	}
+	s := p.Snapshot()
+	if len(s) != 3 {
+		t.Fatalf("expected 3 elements, found %d", len(s))
+	}
+	for i, name := range []string{"one", "two", "three"} {
+		if s[i] == nil || s[i].Name != name {
+			t.Fatalf("expected %q at %d, found %v", name, i, s[i])
+		}
+	}
+	if fedCount := p.Stats().Fed; fedCount != 3 {
+		t.Fatalf("expected the nil elements not to be counted as fed, found %d", fedCount)
+	}
+}
+
+func TestWithOnClose(t *testing.T) {
+	for _, byCancel := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		closed := make(chan PoolStats, 2)
+		p, err := NewPoolWithOptions[int](ctx, Policy{Count: 2}, WithData(0, 1),
+			WithOnClose[int](func(final PoolStats) {
+				closed <- final
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Append(ctx, 2); err != nil {
+			t.Fatal(err)
+		}
+		if byCancel {
+			cancel()
+		} else {
+			p.Close()
+			p.Close()
+		}
+		select {
+		case final := <-closed:
+			// the elements held at shutdown are evicted
+			if final.Fed != 3 || final.Evicted != 3 || final.Length != 2 || final.NextOffset != 3 {
+				t.Fatalf("cancel %v: unexpected final stats %+v", byCancel, final)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("cancel %v: OnClose was not called", byCancel)
+		}
+		// OnClose is the last thing the pool thread does, so a second call would already be buffered
+		cancel()
+		p.WaitForClose()
+		if n := len(closed); n != 0 {
+			t.Fatalf("cancel %v: expected OnClose to be called once, called %d more times", byCancel, n)
+		}
+	}
+}
+
+func TestWithInitialOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithInitialOffset[int](5000), WithData(10, 11, 12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := p.FirstOffset(); first != 5000 {
+		t.Fatalf("expected first offset 5000, found %d", first)
+	}
+	if err := p.Append(ctx, 13); err != nil {
+		t.Fatal(err)
+	}
+	if next := p.NextOffset(); next != 5004 {
+		t.Fatalf("expected next offset 5004, found %d", next)
+	}
+	if v, ok := p.TryRead(5002); !ok || v != 12 {
+		t.Fatalf("expected 12 at offset 5002, found %d, %v", v, ok)
+	}
+	if v := <-p.Read(ctx, 5001); v != 11 {
+		t.Fatalf("expected a read from 5001 to start at 11, found %d", v)
+	}
+	if _, errc := p.ReadWithErr(ctx, 4999); !errors.Is(<-errc, ErrOffsetEvicted) {
+		t.Fatal("expected an offset before the initial offset to be reported as evicted")
+	}
+
+	// a negative initial offset is treated as zero
+	p, err = NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithInitialOffset[int](-5), WithData(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := p.FirstOffset(); first != 0 {
+		t.Fatalf("expected first offset 0, found %d", first)
+	}
+}
+
+func TestWithOnEvict_PanicIsRecovered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := &captureLogger{}
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 2}, WithLogger[int](logger),
+		WithOnEvict(func(i int) {
+			panic("evict failed")
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := p.Snapshot(); len(s) != 2 || s[0] != 3 || s[1] != 4 {
+		t.Fatalf("expected the pool to keep applying its policy, found %v", s)
+	}
+	if v := <-p.Read(ctx, -1); v != 3 {
+		t.Fatalf("expected the pool to keep serving readers, found %d", v)
+	}
+	if !logger.contains("OnEvict callback panicked: evict failed") {
+		t.Fatalf("expected the panic to be logged, found %q", logger.messages)
+	}
+}
+
+func TestPolicy_SizeRejectsOversizedElement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := &captureLogger{}
+	sink := make(chan []byte, 10)
+	p, err := NewPoolWithOptions[[]byte](ctx, Policy{Size: 10}, WithLogger[[]byte](logger),
+		WithOverflowSink[[]byte](sink), WithData([]byte("abc"), []byte("def")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(ctx, make([]byte, 11)); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Snapshot(); len(s) != 2 || string(s[0]) != "abc" {
+		t.Fatalf("expected the held elements to be kept, found %q", s)
+	}
+	select {
+	case b := <-sink:
+		if len(b) != 11 {
+			t.Fatalf("expected the oversized element on the sink, found %q", b)
+		}
+	default:
+		t.Fatal("expected the oversized element to be sent to the sink")
+	}
+	if stats := p.Stats(); stats.Evicted != 1 || stats.NextOffset != 2 {
+		t.Fatalf("expected the element rejected without an offset, found %+v", stats)
+	}
+	if !logger.contains("element of 11 bytes rejected as larger than policy Size 10") {
+		t.Fatalf("expected the rejection to be logged, found %q", logger.messages)
+	}
+	// an element of exactly the Size is held, alone
+	if err := p.Append(ctx, make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Snapshot(); len(s) != 1 || len(s[0]) != 10 {
+		t.Fatalf("expected only the element of the full Size, found %q", s)
+	}
+}
+
+type traceKey struct{}
+
+func TestWithOnDeliver_SeesReaderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	traced := map[string][]int{}
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithData(0, 1, 2),
+		WithOnDeliver(func(ctx context.Context, i int) {
+			mu.Lock()
+			defer mu.Unlock()
+			span, _ := ctx.Value(traceKey{}).(string)
+			traced[span] = append(traced[span], i)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, span := range []string{"a", "b"} {
+		all, err := p.ReadAll(context.WithValue(ctx, traceKey{}, span), 0, 3)
+		if err != nil || len(all) != 3 {
+			t.Fatalf("expected 3 elements, found %v, %v", all, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, span := range []string{"a", "b"} {
+		if d := traced[span]; len(d) != 3 || d[0] != 0 || d[2] != 2 {
+			t.Fatalf("expected the deliveries of span %q traced, found %v", span, traced)
+		}
+	}
+	if len(traced) != 2 {
+		t.Fatalf("expected only the two spans traced, found %v", traced)
+	}
+}
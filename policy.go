@@ -1,16 +1,101 @@
 package pools
 
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
 const defaultPolicySize = 1024 * 1024 * 8 // 8k size default
 
 var DefaultPolicy = Policy{
 	Size: defaultPolicySize,
 }
 
+// Overflow defines how a pool behaves once it holds its policy Count.
+type Overflow int
+
+const (
+	// OverflowDropOldest removes the oldest elements to make room for new ones.
+	OverflowDropOldest Overflow = iota
+	// OverflowBlock stops accepting new elements until the oldest have been read by at least one reader.
+	// A reader's progress is learned as it requests more, so room is made once a reader has received
+	// the whole delivery the oldest elements were part of, of up to maxPostBatch elements, rather than as each is read.
+	OverflowBlock
+	// OverflowDropNewest rejects new elements, keeping the oldest. A rejected element is never held, so takes no offset,
+	// and is counted as evicted and passed to any overflow sink, but not to OnEvict.
+	OverflowDropNewest
+)
+
 type Policy struct {
+	// Size, when greater than zero, limits the total byte size of the elements held.
+	// An element which is, alone, larger than Size is rejected as it is fed, counted as evicted and passed to any
+	// overflow sink, rather than emptying the pool.
 	Size  uint64
 	Count int
+	// MaxAge is the longest duration an item is kept in the pool, regardless of Size or Count.
+	MaxAge time.Duration
+	// Overflow is the behaviour of the pool once it has reached its Count.
+	Overflow Overflow
+	// MaxReaders, when greater than zero, limits the number of concurrent readers of the pool.
+	MaxReaders int
+	// MaxRatePerSec, when greater than zero, limits the rate elements are accepted into the pool.
+	// Elements beyond the rate are rejected with an OverflowDropNewest policy, otherwise feeds block until the rate allows.
+	MaxRatePerSec float64
+	// EvictConsumed, when true, removes each element once every active reader has read it, ahead of Size, Count and
+	// MaxAge, which continue to limit the elements held. Elements are kept while the pool has no readers.
+	// A reader's progress is learned as it requests more, so elements are removed once every reader has received
+	// the whole delivery they were part of. A reader never reads the elements before where it started, so while it is
+	// active, they and the elements following them are kept.
+	EvictConsumed bool
+	// IdleTimeout, when greater than zero, shuts down the pool once it has been idle for the duration,
+	// with no elements fed, no readers attached and no calls made to its methods.
+	IdleTimeout time.Duration
+}
+
+// MinMaxAge is the shortest MaxAge a Policy may have.
+const MinMaxAge = time.Millisecond
+
+// IsConstrained checks if the policy limits the memory of a pool, by at least one of Size, Count or MaxAge.
+func (pl Policy) IsConstrained() bool {
+	return pl.Size > 0 || pl.Count > 0 || pl.MaxAge > 0
 }
 
+// IsConstrainded checks if the policy limits the memory of a pool.
+//
+// Deprecated: Use IsConstrained.
 func (pl Policy) IsConstrainded() bool {
-	return pl.Size > 0 || pl.Count > 0
+	return pl.IsConstrained()
+}
+
+// Validate checks the policy is usable by a pool, returning an error describing the first problem found.
+func (pl Policy) Validate() error {
+	if pl.Count < 0 {
+		return fmt.Errorf("%w: Count %d is negative", ErrInvalidPolicy, pl.Count)
+	}
+	if pl.MaxAge < 0 {
+		return fmt.Errorf("%w: MaxAge %v is negative", ErrInvalidPolicy, pl.MaxAge)
+	}
+	if pl.MaxReaders < 0 {
+		return fmt.Errorf("%w: MaxReaders %d is negative", ErrInvalidPolicy, pl.MaxReaders)
+	}
+	if pl.IdleTimeout < 0 {
+		return fmt.Errorf("%w: IdleTimeout %v is negative", ErrInvalidPolicy, pl.IdleTimeout)
+	}
+	if pl.MaxRatePerSec < 0 || math.IsNaN(pl.MaxRatePerSec) {
+		return fmt.Errorf("%w: MaxRatePerSec %v is not valid", ErrInvalidPolicy, pl.MaxRatePerSec)
+	}
+	if pl.Overflow < OverflowDropOldest || pl.Overflow > OverflowDropNewest {
+		return fmt.Errorf("%w: unknown Overflow %d", ErrInvalidPolicy, pl.Overflow)
+	}
+	if pl.Overflow != OverflowDropOldest && pl.Count == 0 {
+		return fmt.Errorf("%w: Overflow requires a Count", ErrInvalidPolicy)
+	}
+	if pl.MaxAge > 0 && pl.MaxAge < MinMaxAge {
+		return fmt.Errorf("%w: MaxAge %v is less than %v", ErrMaxAgeTooShort, pl.MaxAge, MinMaxAge)
+	}
+	if !pl.IsConstrained() {
+		return ErrUnconstrainedPolicy
+	}
+	return nil
 }
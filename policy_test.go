@@ -0,0 +1,259 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOverflowBlock_RoomMadePerDelivery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+	p := MustNewPool[int](ctx, Policy{Count: 100, Overflow: OverflowBlock}, items...)
+	r := p.ReadN(ctx, 0, 100)
+	<-r
+
+	feed := make(chan int, 1)
+	feed <- 100
+	p.Feed(ctx, feed)
+	waitForWaitingFeeds(t, ctx, p, 1)
+	if n := p.FirstOffset() + int64(p.Len()); n != 100 {
+		t.Fatalf("expected the feed to block until the reader completes its delivery, found %d fed", n)
+	}
+	for i := 1; i < 100; i++ {
+		<-r
+	}
+	waitForFed(t, p, 101)
+}
+
+// waitForWaitingFeeds waits until n elements are waiting to be sent to the pool, then for the pool thread to run a
+// command, so the pool is seen as it is while the elements wait.
+func waitForWaitingFeeds[T any](t *testing.T, ctx context.Context, p Pool[T], n int64) {
+	t.Helper()
+	pl := p.(*pool[T])
+	deadline := time.After(2 * time.Second)
+	for pl.feeding.Load() < n {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d elements to wait on the pool, found %d", n, pl.feeding.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if err := p.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPolicy_MaxAgeIsConstrained(t *testing.T) {
+	if !(Policy{MaxAge: time.Minute}).IsConstrainded() {
+		t.Fatal("expected a MaxAge to constrain the policy")
+	}
+	if (Policy{}).IsConstrainded() {
+		t.Fatal("expected an empty policy to be unconstrained")
+	}
+}
+
+func TestPolicy_MaxAgeExpiresElements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := newFakeClock()
+	p, err := NewPoolWithOptions[int](ctx, Policy{MaxAge: time.Hour}, WithData(1, 2), withClock[int](clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(30 * time.Minute)
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Len(); n != 3 {
+		t.Fatalf("expected no elements to expire yet, found %d held", n)
+	}
+
+	clock.Advance(31 * time.Minute)
+	if err := p.Append(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Snapshot(); len(s) != 2 || s[0] != 3 || s[1] != 4 {
+		t.Fatalf("expected the initial elements to expire, leaving [3 4], found %v", s)
+	}
+	if first := p.FirstOffset(); first != 2 {
+		t.Fatalf("expected first offset 2, found %d", first)
+	}
+}
+
+func TestPolicy_MaxAgeExpiresWithoutFeeds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := newFakeClock()
+	p, err := NewPoolWithOptions[int](ctx, Policy{MaxAge: time.Hour}, WithData(1, 2), withClock[int](clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each query resets the expiry timer, which, once the elements are past their age, fires at once
+	clock.Advance(2 * time.Hour)
+	deadline := time.After(2 * time.Second)
+	for p.Len() > 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the initial elements to expire, found %v", p.Snapshot())
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	// with the initial elements expired, the first available element is the next one fed
+	out := p.Read(ctx, -1)
+	feed := make(chan int, 1)
+	feed <- 3
+	p.Feed(ctx, feed)
+	if v := <-out; v != 3 {
+		t.Fatalf("expected the initial elements to expire, read %d", v)
+	}
+}
+
+func TestOverflowBlock_FeederResumesOnceRead(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 2, Overflow: OverflowBlock}, 0, 1)
+	feed := make(chan int, 1)
+	feed <- 2
+	p.Feed(ctx, feed)
+	waitForWaitingFeeds(t, ctx, p, 1)
+	if n := p.Len(); n != 2 {
+		t.Fatalf("expected the feed to block on a full pool, found %d held", n)
+	}
+	r := p.ReadN(ctx, 0, 3)
+	for i := 0; i < 3; i++ {
+		select {
+		case v := <-r:
+			if v != i {
+				t.Fatalf("expected %d, found %d", i, v)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %d to be fed once the pool was read", i)
+		}
+	}
+	if s := p.Snapshot(); len(s) > 2 {
+		t.Fatalf("expected no more than 2 elements held, found %v", s)
+	}
+}
+
+func TestOverflowBlock_ReaderStartingMidPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 5, Overflow: OverflowBlock}, 1, 2, 3, 4, 5)
+	r := p.Read(ctx, -3)
+	for _, want := range []int{4, 5} {
+		if v := <-r; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+	// wait for the reader to request the offset following the last element, reporting what it has read
+	for lags := p.ReaderLags(); lags[0] != 0; lags = p.ReaderLags() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the elements ahead of where the reader started remain unread, so the pool stays full
+	appended := make(chan error, 1)
+	go func() {
+		appended <- p.Append(ctx, 6)
+	}()
+	waitForWaitingFeeds(t, ctx, p, 1)
+	if s := p.Snapshot(); len(s) != 5 || s[0] != 1 {
+		t.Fatalf("expected the unread elements to be kept, found %v", s)
+	}
+
+	// once read from the start, room is made
+	first := p.Read(ctx, -1)
+	for _, want := range []int{1, 2, 3, 4, 5} {
+		if v := <-first; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+	if err := <-appended; err != nil {
+		t.Fatal(err)
+	}
+	if v := <-r; v != 6 {
+		t.Fatalf("expected 6, found %d", v)
+	}
+}
+
+func TestOverflowDropNewest_KeepsOldest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3, Overflow: OverflowDropNewest}, 0, 1)
+	feed := make(chan int)
+	p.Feed(ctx, feed)
+	// once the last is sent, the feeder has handed each of the preceding elements to the pool
+	for i := 2; i < 11; i++ {
+		feed <- i
+	}
+	if s := p.Snapshot(); len(s) != 3 || s[0] != 0 || s[2] != 2 {
+		t.Fatalf("expected [0 1 2], found %v", s)
+	}
+	if next := p.FirstOffset() + int64(p.Len()); next != 3 {
+		t.Fatalf("expected rejected elements to take no offset, found next offset %d", next)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		want   error
+	}{
+		{"valid count", Policy{Count: 10}, nil},
+		{"valid size", Policy{Size: 100}, nil},
+		{"valid max age", Policy{MaxAge: time.Second}, nil},
+		{"unconstrained", Policy{}, ErrUnconstrainedPolicy},
+		{"negative count", Policy{Count: -1}, ErrInvalidPolicy},
+		{"negative max age", Policy{Count: 1, MaxAge: -time.Second}, ErrInvalidPolicy},
+		{"max age too short", Policy{MaxAge: time.Microsecond}, ErrMaxAgeTooShort},
+		{"negative max readers", Policy{Count: 1, MaxReaders: -1}, ErrInvalidPolicy},
+		{"unknown overflow", Policy{Count: 1, Overflow: Overflow(99)}, ErrInvalidPolicy},
+		{"overflow without count", Policy{Size: 10, Overflow: OverflowBlock}, ErrInvalidPolicy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.want == nil && err != nil {
+				t.Fatalf("expected no error, found %v", err)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, found %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_IsConstraindedAlias(t *testing.T) {
+	for _, policy := range []Policy{{}, {Count: 1}, {Size: 1}, {MaxAge: time.Second}} {
+		if policy.IsConstrainded() != policy.IsConstrained() {
+			t.Fatalf("expected the deprecated alias to agree for %+v", policy)
+		}
+	}
+}
+
+func TestNewPool_InvalidPolicy(t *testing.T) {
+	if _, err := NewPool[int](context.Background(), Policy{Count: -1}); !errors.Is(err, ErrInvalidPolicy) {
+		t.Fatalf("expected ErrInvalidPolicy, found %v", err)
+	}
+}
+
+func TestOverflowDropNewest_TruncatesInitialData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3, Overflow: OverflowDropNewest}, 0, 1, 2, 3, 4)
+	if s := p.Snapshot(); len(s) != 3 || s[0] != 0 || s[2] != 2 {
+		t.Fatalf("expected [0 1 2], found %v", s)
+	}
+	p.Close()
+	// the 3 elements held at shutdown are evicted with the 2 truncated
+	if stats := p.WaitForCloseStats(); stats.Evicted != 5 {
+		t.Fatalf("expected 5 evicted, found %+v", stats)
+	}
+}
@@ -2,213 +2,1875 @@ package pools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// defaultRequestBuffer is the default size of the queue of requests waiting to be serviced by the pool thread.
+const defaultRequestBuffer = 10
+
+// maxPostBatch is the most elements delivered to a reader before its request is queued behind the other readers.
+const maxPostBatch = 64
+
+// quiescePoll is the interval Quiesce checks if the pool has become idle.
+const quiescePoll = time.Millisecond
+
+// ReadLatest may be used as a Read offset to ignore all existing data, reading only elements fed after the Read began.
+const ReadLatest = math.MinInt64
+
 // Pool represents an active slice of data which can be read and appended to by multiple, concurrent users.
 // It uses a non-blocking model, such that no client reader or writer can block the process of any other process.
 // e.g. if a Reader blocks its receiving channel, the other Readers will still continue to read.
 // The content data's life cycle is governed by a 'Policy' which defines both how long to keep items and/or how many to keep.
 // The contents are zero based indexed, like a reqular slice, however, as the Pool Policy dictates, early indexes
 // may be removed and become unavailable to any future readers.
-// A Reader must ask for the starting index and if that index is no longer in the pool,
+// A Reader must ask for the starting index and if that index is no longer in the pool, the Read is ended with ErrOffsetEvicted.
+// An index which has not yet been fed into the pool is not an error, the Reader waits until it is fed.
+// A negative index is relative: -1 reads from the first available element, while -1-n reads from n elements before
+// the end of the pool, e.g. -11 reads the 10 most recent elements and on, as 'tail -n 10' would.
+// Readers are serviced in turn, each being delivered a bounded batch of elements before the next is serviced,
+// so a slow or far behind Reader can not starve the other Readers.
 type Pool[T any] interface {
 	Policy() Policy
+	// SetPolicy replaces the policy of the pool, immediately applying it to the current elements.
+	// An error is returned if the policy is not valid or the pool has shutdown.
+	SetPolicy(policy Policy) error
+	// Feed feeds all the elements received from the given channel into the pool, until the channel is closed,
+	// the context is cancelled or the pool shuts down. The returned channel is closed when the pool shuts down.
+	// Elements from a single channel are added in the order they are received, however elements of concurrent Feeds
+	// are interleaved in no defined order. See FeedOrdered to track the order of each source.
+	// A channel which is abandoned without being closed keeps the feed running for as long as the context and pool,
+	// use FeedWithIdle where the source may stop sending without closing.
+	// Feed may be called at any time, as feeds end when the pool shuts down, never sending to a shutdown pool.
+	// Feeding the same channel more than once is permitted, each element being fed once, by whichever feed receives it.
 	Feed(ctx context.Context, ch <-chan T) <-chan struct{}
-	Read(ctx context.Context, offset int) <-chan T
+	// FeedOnce feeds in the same way as Feed, returning ErrAlreadyFed if the channel is still being fed by an earlier FeedOnce.
+	// The returned channel is closed once the feed has ended, after which the channel may be fed again.
+	FeedOnce(ctx context.Context, ch <-chan T) (<-chan struct{}, error)
+	// FeedWithIdle feeds in the same way as Feed, also ending the feed if no element is received from the channel
+	// within the idle duration. The returned channel is closed once the feed has ended.
+	FeedWithIdle(ctx context.Context, ch <-chan T, idle time.Duration) <-chan struct{}
+	// FeedCounted feeds in the same way as Feed, also returning a function which reports how many elements the pool has accepted.
+	// An element is accepted once the pool has added it, so elements the pool rejects, such as those beyond the Count
+	// of an OverflowDropNewest policy, or zero values skipped by WithSkipZero, are not counted.
+	// The returned channel is closed once the feed has ended, after which the accepted count is final.
+	FeedCounted(ctx context.Context, ch <-chan T) (<-chan struct{}, func() int)
+	// FeedRecoverable feeds in the same way as Feed, also returning a function which recovers any element the feed had
+	// received from the channel, but which the pool had not accepted, when the feed ended. The returned done channel is
+	// closed once the feed ends, and the function blocks until it has, returning false if no element was left unsent.
+	// Elements remaining in the channel are left there, so the caller may pass them, after any unsent element, elsewhere.
+	FeedRecoverable(ctx context.Context, ch <-chan T) (<-chan struct{}, func() (T, bool))
+	// FeedSlice feeds all the given elements into the pool together, so they are held in order, with no other elements
+	// between them. An error is returned if the pool has shutdown or the context is cancelled.
+	// Under an OverflowBlock policy with a Count, each element is added only once there is room for it, so a slice
+	// larger than the Count is added as readers make room, with no other feed accepted until all of it is held.
+	// FeedSlice returns once the pool has taken the slice, and any elements still waiting for room when the pool
+	// shuts down are discarded.
+	FeedSlice(ctx context.Context, items []T) error
+	// FeedBatches feeds in the same way as Feed, each slice received from the channel being fed together, as with FeedSlice.
+	// A slice must not be changed once it has been sent.
+	FeedBatches(ctx context.Context, ch <-chan []T) <-chan struct{}
+	// Append feeds a single element into the pool, returning an error if the pool has shutdown or the context is cancelled.
+	Append(ctx context.Context, item T) error
+	// Read delivers each element, starting at the given offset, on the returned channel.
+	// The channel is closed once the read ends, by its context being cancelled, its offset being evicted or the pool
+	// shutting down. On shutdown, the channel is closed promptly, without waiting to deliver any remaining elements.
+	// The read is serviced with the given context throughout, so its values are seen by any OnDeliver hook.
+	Read(ctx context.Context, offset int64) <-chan T
+	// ReadWithErr reads in the same way as Read, also returning a channel which receives any error which ends the read.
+	// The error is sent before the data channel is closed. A read ended by its context sends no error.
+	ReadWithErr(ctx context.Context, offset int64) (<-chan T, <-chan error)
+	// ReadN reads, at most, n elements, starting at the given offset.
+	// The returned channel is closed once n elements have been delivered.
+	ReadN(ctx context.Context, offset int64, n int) <-chan T
+	// ReadNWithErr reads in the same way as ReadN, also returning a channel which receives any error which ends the read.
+	// Once n elements have been delivered, ErrComplete is sent, so a completed read can be told from one cancelled.
+	ReadNWithErr(ctx context.Context, offset int64, n int) (<-chan T, <-chan error)
+	// ReadAll reads, at most, max elements, starting at the given offset, returning them once max have been read
+	// or the pool shuts down. If max is less than one, all elements are read until the pool shuts down.
+	// Should the context be cancelled, or the read fail, the elements read so far are returned with the error.
+	ReadAll(ctx context.Context, offset int64, max int) ([]T, error)
+	// ReadPriority reads in the same way as Read, with the given priority. A read with a priority greater than zero
+	// is serviced ahead of all reads without, so a critical reader is delivered new elements first.
+	// Priority reads are serviced whenever they are waiting, so many busy priority reads can starve the other reads.
+	ReadPriority(ctx context.Context, offset int64, prio int) <-chan T
+	// ReadWithTimeout reads in the same way as Read, ending the read should any element not be received within the
+	// timeout of being sent, so a reader which abandons the channel without cancelling its context is ended.
+	ReadWithTimeout(ctx context.Context, offset int64, timeout time.Duration) <-chan T
+	// ReadBuffered reads in the same way as Read, returning a channel buffered with the given size.
+	// A buffered channel allows a bursty reader to fall behind without blocking the delivery of elements.
+	ReadBuffered(ctx context.Context, offset int64, bufSize int) <-chan T
+	// ReadGapTolerant reads in the same way as Read, however, rather than ending when its offset is evicted,
+	// it jumps forward to the first available offset, reporting the missed offsets on the returned GapEvent channel.
+	// The GapEvent channel should be received from alongside the data channel, as delivery waits for each GapEvent to be received.
+	ReadGapTolerant(ctx context.Context, offset int64) (<-chan T, <-chan GapEvent)
+	// ReadFrom reads in the same way as Read, starting at the given StartPosition.
+	ReadFrom(ctx context.Context, pos StartPosition) <-chan T
+	// ReadSince reads in the same way as Read, starting at the first element fed into the pool at or after the given time.
+	// If the time precedes the earliest element held, reading starts at the earliest. If no element has been fed since the
+	// time, only elements fed after the call are read.
+	ReadSince(ctx context.Context, since time.Time) <-chan T
+	// Subscribe calls the given function with each element, starting at the given offset, until the returned cancel
+	// function is called, the context is cancelled, or the pool shuts down.
+	// fn is called on a single goroutine, in offset order, and must not block indefinitely.
+	// Once cancel returns, fn is no longer called. cancel must not be called from within fn.
+	// Should fn panic, the panic is recovered and logged, and fn is called with the following elements.
+	Subscribe(ctx context.Context, offset int64, fn func(T)) (cancel func())
+	// ReadBatch reads in the same way as Read, delivering the elements in slices of, at most, maxBatch elements.
+	// Each slice holds the elements available when it was built, so is never empty and is owned by the receiver.
+	// If maxBatch is less than one, a default batch size is used.
+	ReadBatch(ctx context.Context, offset int64, maxBatch int) <-chan []T
+	// ReadIndexed reads in the same way as Read, delivering each element with its offset, so a reader may record
+	// precisely where it has read to. Offsets of removed elements, which are passed over, are not delivered.
+	ReadIndexed(ctx context.Context, offset int64) <-chan Indexed[T]
+	// Cursor reads in the same way as Read, returning a Cursor to pull each element in turn, in place of a channel.
+	// A negative offset is resolved to its absolute offset when the Cursor is created.
+	Cursor(ctx context.Context, offset int64) Cursor[T]
+	// ReadRecent delivers, at most, the n most recent elements, newest first.
+	// The channel is closed once they are delivered, elements fed after the call are not delivered.
+	ReadRecent(ctx context.Context, n int) <-chan T
+	// ReaderLags returns, for each active reader, the number of elements it is behind the NextOffset of the pool,
+	// measured from the offset it last requested. The lags are in no defined order.
+	// A lag approaching the policy Count, or the pool length, warns of a reader about to have its offset evicted.
+	ReaderLags() []int
+	// Readers returns the active readers of the pool, in no defined order.
+	Readers() []ReaderInfo
+	// TrimEvents returns a channel reporting each time the policy removes elements from the pool, or Flush or Reset
+	// remove all those held.
+	// Events are dropped, rather than waiting, when the channel is not received from promptly, so the pool is never stalled.
+	// The channel is closed once the pool has shutdown.
+	TrimEvents() <-chan TrimEvent
+	// Stats returns a snapshot of the current stats of the pool, all sampled at the same point.
+	// Once the pool has shutdown, its final stats are returned, counting the elements evicted at shutdown.
+	Stats() PoolStats
+	// Contains checks if the element at the given offset is currently held in the pool.
+	Contains(offset int64) bool
+	// IndexFor returns the index, relative to FirstOffset, of the element at the given offset.
+	// false is returned if the offset has been evicted or removed, has not yet been fed, or the pool has shutdown.
+	// The index is only valid until the pool next changes, as evictions move the first offset on.
+	IndexFor(offset int64) (int, bool)
+	// TryRead returns the element at the given offset, without waiting.
+	// false is returned if the offset has not yet been fed, has been removed, or the pool has shutdown.
+	TryRead(offset int64) (T, bool)
+	// Len returns the number of elements currently held in the pool.
+	Len() int
+	// FirstOffset returns the offset of the earliest element still held in the pool.
+	FirstOffset() int64
+	// NextOffset returns the offset the next element fed into the pool will occupy.
+	// It only increases, regardless of elements being removed, so may be recorded as a position to resume reading from,
+	// until the pool is Reset, which restarts offsets at zero. Recorded with PoolStats.Resets, from the same Stats,
+	// a resuming reader can tell whether the offset still refers to the same data.
+	NextOffset() int64
+	// Snapshot returns a copy of all the elements currently held in the pool.
+	Snapshot() []T
+	// ReadWindow returns a copy of the elements held in the offsets from, up to but not including, to.
+	// The window is clamped to the offsets held, so elements already evicted, or not yet fed, are left out.
+	// ErrOffsetEvicted is returned if every offset of a non-empty window has been evicted.
+	ReadWindow(from, to int64) ([]T, error)
+	// MarshalSnapshot returns the JSON encoding of the elements currently held in the pool, with the offset of the first.
+	// The snapshot may be loaded into a new pool with LoadPool. T must be able to be marshalled to JSON.
+	MarshalSnapshot() ([]byte, error)
+	// Flush removes all the elements currently held in the pool, leaving it open to be fed new elements.
+	// The removed elements are passed to any OnEvict and reported as a TrimEvent, as if trimmed by the policy, but,
+	// being removed by the caller, are not sent to any overflow sink.
+	Flush()
+	// Reset removes all the elements currently held in the pool, as Flush, then restarts its offsets at zero, holding
+	// the given data. The removed elements are passed to any OnEvict. The offset of an active reader no longer refers
+	// to the same element, so, as it next requests more, a gap tolerant reader receives a GapEvent, marked as a Reset,
+	// and continues from the first of the new data, while any other reader ends with ErrOffsetEvicted. Elements handed
+	// to a reader before the reset may still be delivered to it first. A reader yet to resolve a relative offset
+	// resolves it against the new data, as a reader made after the reset would.
+	// As offsets restart, NextOffset goes back, and an offset recorded before the reset refers to the new data. A reader
+	// resuming from such an offset detects the reset by comparing PoolStats.Resets, recorded with the offset, to the
+	// current count, as only readers active during the reset receive the GapEvent.
+	// An error is returned if the pool has shutdown, or the context is cancelled before the pool is reset.
+	Reset(ctx context.Context, data ...T) error
+	// WaitForData blocks until the pool holds an element at, or after, the given offset.
+	// An error is returned if the context is cancelled or the pool shuts down before then.
+	WaitForData(ctx context.Context, offset int64) error
+	// WaitForOffset blocks until the given offset has been fed into the pool, i.e. NextOffset is greater than offset.
+	// An offset already fed returns immediately, even if it has since been removed. A Reset restarts offsets at zero,
+	// so an offset recorded before a reset waits for the same offset of the new data.
+	// An error is returned if the context is cancelled or the pool shuts down before then.
+	WaitForOffset(ctx context.Context, offset int64) error
+	// Quiesce blocks until the pool is idle, with every fed element added to the pool and every reader waiting for new
+	// elements, having been delivered all those held. It is intended for tests, to assert on a stable state of the pool.
+	// Fed elements include those waiting to be sent by Append, FeedSlice or a feed, once the feed has received them
+	// from its channel. Elements still to be received from a fed channel are not known to the pool.
+	// A reader which is not received from prevents the pool becoming idle.
+	// An error is returned if the context is cancelled or the pool shuts down before then.
+	Quiesce(ctx context.Context) error
+	// Ping checks the pool thread is responsive, waiting for it to run a command which does nothing.
+	// An error is returned if the pool has shutdown, or the context is cancelled before the pool responds,
+	// so a context with a timeout may be used to detect a pool which is stuck, as a liveness probe.
+	Ping(ctx context.Context) error
+	// Close shuts down the pool, in the same way as cancelling its context.
+	// Close may be called more than once.
+	Close()
+	// CloseAndDrain stops the pool accepting new elements, continuing to service the active readers until they have
+	// all been delivered the remaining elements, at which point the pool shuts down.
+	// If the context is cancelled before then, the pool is shut down immediately and the context error returned.
+	CloseAndDrain(ctx context.Context) error
 	WaitForClose()
+	// WaitForCloseContext blocks in the same way as WaitForClose, returning the context error should the context
+	// be cancelled before the pool has shutdown.
+	WaitForCloseContext(ctx context.Context) error
+	// WaitForCloseStats blocks in the same way as WaitForClose, returning the final stats of the closed pool.
+	WaitForCloseStats() PoolStats
+	// ReadOnly returns a view of the pool which may be read, but not fed or closed.
+	ReadOnly() ReadOnlyPool[T]
+}
+
+// command is a function run on the pool thread, with sole access to the pool data.
+type command[T any] func(data *offsetData[T])
+
+// waitLock holds the channel requests wait on for new data. The channel is closed, and cleared, as new data is fed.
+type waitLock struct {
+	mu sync.Mutex
+	ch chan struct{}
 }
 
 type pool[T any] struct {
-	feed chan T
-	done chan struct{}
+	feed      chan T        // never closed, feeders end once done is closed, so never send on a closed channel
+	feedBatch chan batch[T] // as feed, for batches of elements
+	feeding   *atomic.Int64 // sends to feed or feedBatch in flight, counted by the feeder and uncounted by the pool thread once fed
+	done      chan struct{} // closed once the pool thread has ended
+	closing   chan struct{}
+	cancelled <-chan struct{} // the done channel of the pool context
+	closeOnce *sync.Once
+	draining  chan struct{}
+	drainOnce *sync.Once
+
+	requests         chan request[T]
+	priorityRequests chan request[T] // serviced ahead of requests
+	commands         chan command[T]
+	waitLock         *waitLock
+
+	policy *Policy // owned by the pool thread
+
+	now     func() time.Time // the clock timing element ages
+	sizer   func(T) uint64
+	onEvict func(T)
+	data    []T
+	offset  int64 // offset of the first element of data
+	logger  Logger
+
+	overflowSink chan<- T // receives elements removed by the policy before being read
+	skipZero     bool     // drop fed elements which are the zero value
+	onClose      func(final PoolStats)
+	onDeliver    func(ctx context.Context, t T) // called by each reader with the elements it delivers
+
+	keyOf func(T) any   // when not nil, the pool keeps only the latest element of each key
+	keys  map[any]int64 // offset of the latest element of each key, owned by the pool thread
+
+	retention RetentionPolicy[T] // when not nil, replaces the policy Size, Count and MaxAge
+
+	queue *queueState[T] // when not nil, the pool is a queue, handing each element to a single reader
+
+	stats     *PoolStats // owned by the pool thread, only read by others once done is closed
+	delivered *atomic.Int64
+
+	firstOffset *atomic.Int64 // published copy of the data offset, for waiting requests to check against
 
-	requests      chan request[T]
-	waitLock      chan struct{}
-	waitLockMutex *sync.Mutex
+	fedChannels *sync.Map // channels being fed by FeedOnce
 
-	policy Policy
+	readers      map[request[T]]int64       // active readers, mapped to the offset they last requested, owned by the pool thread
+	readerStarts map[request[T]]readerStart // where and when each active reader started, owned by the pool thread
+	resets       *int                       // the number of times the pool has been reset, owned by the pool thread
+	readerResets map[request[T]]int         // the resets each active reader's offset refers to, owned by the pool thread
+
+	trimEvents chan TrimEvent // closed once the pool thread has ended
+	trimmed    *TrimEvent     // elements removed by the current trim, owned by the pool thread
+}
+
+func (p pool[T]) ReadOnly() ReadOnlyPool[T] {
+	return readOnly[T]{p: p}
+}
+
+func (p pool[T]) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+	})
+}
+
+func (p pool[T]) CloseAndDrain(ctx context.Context) error {
+	p.drainOnce.Do(func() {
+		close(p.draining)
+	})
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		p.Close()
+		<-p.done
+		return ctx.Err()
+	}
 }
 
 func (p pool[T]) WaitForClose() {
+	_ = p.WaitForCloseContext(context.Background())
+}
+
+func (p pool[T]) WaitForCloseContext(ctx context.Context) error {
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p pool[T]) WaitForCloseStats() PoolStats {
 	<-p.done
+	return *p.stats
 }
 
 func (p pool[T]) Policy() Policy {
-	return p.policy
+	var pl Policy
+	if !p.query(func(data *offsetData[T]) {
+		pl = *p.policy
+	}) {
+		// pool has shutdown, so its policy can no longer change
+		pl = *p.policy
+	}
+	return pl
+}
+
+func (p pool[T]) SetPolicy(policy Policy) error {
+	if err := p.validatePolicy(policy); err != nil {
+		return err
+	}
+	if !p.query(func(data *offsetData[T]) {
+		*p.policy = policy
+		p.applyPolicy(data)
+	}) {
+		return fmt.Errorf("policy not set as %w", ErrPoolClosed)
+	}
+	return nil
+}
+
+// validatePolicy checks the policy is valid, and supported by the pool.
+func (p pool[T]) validatePolicy(policy Policy) error {
+	if err := policy.Validate(); err != nil && !(p.retention != nil && errors.Is(err, ErrUnconstrainedPolicy)) {
+		return err
+	}
+	if p.keyOf != nil && policy.Overflow != OverflowDropOldest {
+		return fmt.Errorf("%w: a keyed pool only supports OverflowDropOldest", ErrInvalidPolicy)
+	}
+	return nil
 }
 
 func (p pool[T]) Feed(ctx context.Context, ch <-chan T) <-chan struct{} {
-	go func(ch <-chan T) {
+	go p.feedFrom(ctx, ch, nil, 0)
+	return p.done
+}
+
+func (p pool[T]) FeedOnce(ctx context.Context, ch <-chan T) (<-chan struct{}, error) {
+	if _, loaded := p.fedChannels.LoadOrStore(ch, struct{}{}); loaded {
+		return nil, ErrAlreadyFed
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer p.fedChannels.Delete(ch)
+		p.feedFrom(ctx, ch, nil, 0)
+	}()
+	return done, nil
+}
+
+func (p pool[T]) FeedWithIdle(ctx context.Context, ch <-chan T, idle time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.feedFrom(ctx, ch, nil, idle)
+	}()
+	return done
+}
+
+func (p pool[T]) FeedCounted(ctx context.Context, ch <-chan T) (<-chan struct{}, func() int) {
+	var accepted atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.feedFrom(ctx, ch, &accepted, 0)
+		// the pool thread has added the last element sent once it runs a following command, so the count is final
+		p.query(func(data *offsetData[T]) {})
+	}()
+	return done, func() int {
+		return int(accepted.Load())
+	}
+}
+
+func (p pool[T]) FeedRecoverable(ctx context.Context, ch <-chan T) (<-chan struct{}, func() (T, bool)) {
+	var unsent T
+	var ok bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		unsent, ok = p.feedFrom(ctx, ch, nil, 0)
+	}()
+	return done, func() (T, bool) {
+		// the result is only set once the feed has ended
+		<-done
+		return unsent, ok
+	}
+}
+
+func (p pool[T]) FeedSlice(ctx context.Context, items []T) error {
+	if len(items) == 0 {
+		return nil
+	}
+	// the pool reads the batch after the send, so it is copied to leave the caller free to change items
+	return p.sendBatch(ctx, batch[T]{items: append([]T(nil), items...)})
+}
+
+func (p pool[T]) FeedBatches(ctx context.Context, ch <-chan []T) <-chan struct{} {
+	go func() {
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-p.done:
 				return
-			case t, ok := <-ch:
+			case items, ok := <-ch:
 				if !ok {
 					return
 				}
-				select {
-				case <-ctx.Done():
-					return
-				case <-p.done:
+				if err := p.sendBatch(ctx, batch[T]{items: items}); err != nil {
 					return
-				case p.feed <- t:
 				}
 			}
 		}
-	}(ch)
+	}()
 	return p.done
 }
 
-func (p pool[T]) Read(ctx context.Context, offset int) <-chan T {
+// sendBatch sends the batch to the pool thread, returning an error if the pool has shutdown or the context is cancelled.
+func (p pool[T]) sendBatch(ctx context.Context, b batch[T]) error {
+	select {
+	case <-p.done:
+		return fmt.Errorf("feed failed as %w", ErrPoolClosed)
+	default:
+	}
+	p.feeding.Add(1)
+	select {
+	case <-ctx.Done():
+		p.feeding.Add(-1)
+		return ctx.Err()
+	case <-p.done:
+		p.feeding.Add(-1)
+		return fmt.Errorf("feed failed as %w", ErrPoolClosed)
+	case p.feedBatch <- b:
+		return nil
+	}
+}
+
+func (p pool[T]) Append(ctx context.Context, item T) error {
+	// checked first, as once the pool is ready to receive, a send is as likely to be chosen as either ending
+	select {
+	case <-p.done:
+		return fmt.Errorf("append failed as %w", ErrPoolClosed)
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.feeding.Add(1)
+	select {
+	case <-ctx.Done():
+		p.feeding.Add(-1)
+		return ctx.Err()
+	case <-p.done:
+		p.feeding.Add(-1)
+		return fmt.Errorf("append failed as %w", ErrPoolClosed)
+	case p.feed <- item:
+		return nil
+	}
+}
+
+// feedFrom feeds the elements from the given channel into the pool, until the channel is closed, the context is cancelled
+// or the pool shuts down. If accepted is not nil, it is incremented with every element the pool receives.
+// If idle is greater than zero, the feed also ends when no element is received from the channel within the idle duration.
+// Should the feed end after receiving an element, but before the pool accepts it, the element is returned with true.
+func (p pool[T]) feedFrom(ctx context.Context, ch <-chan T, accepted *atomic.Int64, idle time.Duration) (unsent T, ok bool) {
+	var timer *time.Timer
+	var idleC <-chan time.Time
+	if idle > 0 {
+		timer = time.NewTimer(idle)
+		defer timer.Stop()
+		idleC = timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return unsent, false
+		case <-p.done:
+			return unsent, false
+		case <-idleC:
+			p.logger.Printf("feed ended after being idle for %v\n", idle)
+			return unsent, false
+		case t, open := <-ch:
+			if !open {
+				return unsent, false
+			}
+			feed, feedBatch := p.feed, chan batch[T](nil)
+			if accepted != nil {
+				// sent as a batch of one, so the pool thread counts the element only once it has added it
+				feed, feedBatch = nil, p.feedBatch
+			}
+			p.feeding.Add(1)
+			select {
+			case <-ctx.Done():
+				p.feeding.Add(-1)
+				return t, true
+			case <-p.done:
+				p.feeding.Add(-1)
+				return t, true
+			case feed <- t:
+			case feedBatch <- batch[T]{items: []T{t}, accepted: accepted}:
+			}
+			if timer != nil {
+				// the idle period starts again once the element has been fed
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(idle)
+			}
+		}
+	}
+}
+
+func (p pool[T]) Read(ctx context.Context, offset int64) <-chan T {
+	ch, _ := p.read(ctx, offset, readConfig[T]{})
+	return ch
+}
+
+func (p pool[T]) ReadWithErr(ctx context.Context, offset int64) (<-chan T, <-chan error) {
+	return p.read(ctx, offset, readConfig[T]{})
+}
+
+func (p pool[T]) ReadAll(ctx context.Context, offset int64, max int) ([]T, error) {
+	ch, errc := p.read(ctx, offset, readConfig[T]{limit: max})
+	var all []T
+	for t := range ch {
+		all = append(all, t)
+	}
+	switch err := <-errc; {
+	case errors.Is(err, ErrComplete), errors.Is(err, ErrPoolClosed):
+		return all, nil
+	case err != nil:
+		return all, err
+	}
+	return all, ctx.Err()
+}
+
+func (p pool[T]) ReadPriority(ctx context.Context, offset int64, prio int) <-chan T {
+	ch, _ := p.read(ctx, offset, readConfig[T]{priority: prio})
+	return ch
+}
+
+func (p pool[T]) ReadWithTimeout(ctx context.Context, offset int64, timeout time.Duration) <-chan T {
+	ch, _ := p.read(ctx, offset, readConfig[T]{stallTimeout: timeout})
+	return ch
+}
+
+func (p pool[T]) ReadBuffered(ctx context.Context, offset int64, bufSize int) <-chan T {
+	if bufSize < 0 {
+		bufSize = 0
+	}
+	ch, _ := p.read(ctx, offset, readConfig[T]{bufSize: bufSize})
+	return ch
+}
+
+func (p pool[T]) ReadN(ctx context.Context, offset int64, n int) <-chan T {
+	ch, _ := p.ReadNWithErr(ctx, offset, n)
+	return ch
+}
+
+func (p pool[T]) ReadNWithErr(ctx context.Context, offset int64, n int) (<-chan T, <-chan error) {
+	if n <= 0 {
+		ch := make(chan T)
+		close(ch)
+		errc := make(chan error, 1)
+		errc <- ErrComplete
+		close(errc)
+		return ch, errc
+	}
+	return p.read(ctx, offset, readConfig[T]{limit: n})
+}
+
+func (p pool[T]) ReadGapTolerant(ctx context.Context, offset int64) (<-chan T, <-chan GapEvent) {
+	gaps := make(chan GapEvent)
+	ch, _ := p.read(ctx, offset, readConfig[T]{gaps: gaps})
+	return ch, gaps
+}
+
+func (p pool[T]) ReadFrom(ctx context.Context, pos StartPosition) <-chan T {
+	switch pos {
+	case FromLatest:
+		return p.Read(ctx, ReadLatest)
+	case FromEarliestFollow:
+		ch, gaps := p.ReadGapTolerant(ctx, -1)
+		go func() {
+			// the gaps are jumped silently, gaps is closed once the read ends
+			for range gaps {
+			}
+		}()
+		return ch
+	default:
+		return p.Read(ctx, -1)
+	}
+}
+
+func (p pool[T]) ReadSince(ctx context.Context, since time.Time) <-chan T {
+	offset := int64(-1)
+	p.query(func(data *offsetData[T]) {
+		if off := data.OffsetSince(since); off > data.Offset() {
+			offset = off
+		}
+	})
+	// reading from the earliest is left to resolve as the read starts, should the earliest be evicted meanwhile
+	return p.Read(ctx, offset)
+}
+
+func (p pool[T]) Subscribe(ctx context.Context, offset int64, fn func(T)) (cancel func()) {
+	ctx, cnl := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func(ch <-chan T) {
+		defer close(done)
+		for t := range ch {
+			if ctx.Err() != nil {
+				return
+			}
+			p.recoverCallback("Subscribe", func() {
+				fn(t)
+			})
+		}
+	}(p.Read(ctx, offset))
+	return func() {
+		cnl()
+		<-done
+	}
+}
+
+func (p pool[T]) ReadBatch(ctx context.Context, offset int64, maxBatch int) <-chan []T {
+	if maxBatch < 1 {
+		maxBatch = maxPostBatch
+	}
+	ch := make(chan []T)
+	p.read(ctx, offset, readConfig[T]{batches: ch, batchSize: maxBatch})
+	return ch
+}
+
+func (p pool[T]) ReadIndexed(ctx context.Context, offset int64) <-chan Indexed[T] {
+	ch := make(chan Indexed[T])
+	p.read(ctx, offset, readConfig[T]{indexed: ch})
+	return ch
+}
+
+func (p pool[T]) Cursor(ctx context.Context, offset int64) Cursor[T] {
+	if offset < 0 {
+		p.query(func(data *offsetData[T]) {
+			offset = resolveOffset(data, offset)
+		})
+	}
+	return newCursor(ctx, p, offset)
+}
+
+func (p pool[T]) ReadRecent(ctx context.Context, n int) <-chan T {
 	ch := make(chan T)
+	var recent []T
+	if n > 0 {
+		p.query(func(data *offsetData[T]) {
+			recent = data.LiveFrom(data.NextOffset() - int64(n))
+		})
+	}
 	go func(out chan<- T) {
 		defer close(out)
+		for i := len(recent) - 1; i >= 0; i-- {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- recent[i]:
+			}
+		}
+	}(ch)
+	return ch
+}
 
-		errs := make(chan error)
-		rq := newRequest(ctx, ch, errs, offset)
-		p.submitRequest(rq)
-		select {
-		case <-ctx.Done():
-			return
-		case err := <-errs:
-			log.Println(err)
+// readConfig defines the optional behaviour of a read.
+type readConfig[T any] struct {
+	// limit, when greater than zero, completes the read once limit elements have been delivered.
+	limit int
+	// stallTimeout, when greater than zero, cancels the read should a delivery not be received within it.
+	stallTimeout time.Duration
+	// priority, when greater than zero, services the read ahead of reads without priority.
+	priority int
+	// bufSize sets the buffer size of the data channel.
+	bufSize int
+	// gaps, when not nil, makes the read jump over evicted offsets, reporting each jump on the channel.
+	gaps chan<- GapEvent
+	// batches, when not nil, receives the data as slices of, at most, batchSize elements, in place of the data channel.
+	batches   chan<- []T
+	batchSize int
+	// indexed, when not nil, receives the data with the offset of each element, in place of the data channel.
+	indexed chan<- Indexed[T]
+}
+
+// read starts a new reader, configured with the given config, servicing its request until the read ends.
+// Any error ending the read is sent on the returned error channel, before the data channel is closed.
+func (p pool[T]) read(ctx context.Context, offset int64, cfg readConfig[T]) (<-chan T, <-chan error) {
+	ch := make(chan T, cfg.bufSize)
+	errc := make(chan error, 1)
+	var cancel context.CancelFunc
+	if cfg.stallTimeout > 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	rq := newRequest(ctx, ch, offset, cfg.limit, cfg.gaps)
+	rq.stallTimeout, rq.cancel = cfg.stallTimeout, cancel
+	rq.batches, rq.batchSize = cfg.batches, cfg.batchSize
+	rq.indexed = cfg.indexed
+	rq.onDeliver = p.onDeliver
+	rq.priority = cfg.priority
+	rq.poolDone = p.done
+	// registered before returning, so a ReadLatest offset is resolved against the data as it is when the read is made
+	regErr := p.registerReader(rq)
+	go func(out chan<- T) {
+		// the reader is the only sender on out and gaps, so they are closed once it has finished
+		defer close(out)
+		defer close(errc)
+		if cfg.gaps != nil {
+			defer close(cfg.gaps)
+		}
+		if cfg.batches != nil {
+			defer close(cfg.batches)
+		}
+		if cfg.indexed != nil {
+			defer close(cfg.indexed)
+		}
+		if cancel != nil {
+			defer cancel()
+		}
+		if regErr != nil {
+			p.logger.Println(regErr)
+			errc <- regErr
 			return
 		}
+		defer p.unregisterReader(rq)
+
+		if err := p.serveRequest(rq); err != nil {
+			p.logger.Println(err)
+			errc <- err
+		} else if rq.IsComplete() {
+			errc <- ErrComplete
+		}
 	}(ch)
-	return ch
+	return ch, errc
+}
+
+// registerReader adds the request to the active readers, returning an error if the policy MaxReaders has been reached.
+// A ReadLatest offset of the request is resolved to its absolute offset.
+func (p pool[T]) registerReader(rq request[T]) error {
+	var err error
+	if !p.query(func(data *offsetData[T]) {
+		if p.policy.MaxReaders > 0 && len(p.readers) >= p.policy.MaxReaders {
+			err = fmt.Errorf("%w: limit of %d readers reached", ErrTooManyReaders, p.policy.MaxReaders)
+			return
+		}
+		if rq.Offset() == ReadLatest {
+			// fixed now, so only elements fed after the read is made are read
+			rq.ResetOffset(resolveOffset(data, rq.Offset()))
+		}
+		p.readers[rq] = rq.Offset()
+		start := rq.Offset()
+		if start < 0 {
+			// any other relative offset is resolved as the read starts, so is not evicted before then
+			start = resolveOffset(data, start)
+		}
+		p.readerStarts[rq] = readerStart{offset: start, at: p.now()}
+		p.readerResets[rq] = *p.resets
+	}) {
+		return errAbortedByShutdown
+	}
+	return err
+}
+
+func (p pool[T]) unregisterReader(rq request[T]) {
+	p.query(func(data *offsetData[T]) {
+		delete(p.readers, rq)
+		delete(p.readerStarts, rq)
+		delete(p.readerResets, rq)
+		if p.policy.EvictConsumed {
+			// the elements the reader held back may now have been read by every remaining reader
+			p.applyPolicy(data)
+		}
+		if p.queue != nil {
+			p.queue.acknowledge(rq)
+			p.consumeQueue(data)
+			// waiting readers are woken to take any element the reader did not receive
+			p.releaseWaitLock()
+		}
+	})
+}
+
+func (p pool[T]) Readers() []ReaderInfo {
+	var infos []ReaderInfo
+	p.query(func(data *offsetData[T]) {
+		infos = make([]ReaderInfo, 0, len(p.readers))
+		now := p.now()
+		for rq, offset := range p.readers {
+			start := p.readerStarts[rq]
+			if offset < 0 {
+				// the reader has yet to make its first request
+				offset = start.offset
+			}
+			infos = append(infos, ReaderInfo{StartOffset: start.offset, CurrentOffset: offset, Age: now.Sub(start.at)})
+		}
+	})
+	return infos
+}
+
+func (p pool[T]) ReaderLags() []int {
+	var lags []int
+	p.query(func(data *offsetData[T]) {
+		lags = make([]int, 0, len(p.readers))
+		for rq, offset := range p.readers {
+			if offset < 0 {
+				// the reader has yet to make its first request
+				offset = p.readerStarts[rq].offset
+			}
+			lag := data.NextOffset() - offset
+			if lag < 0 {
+				// a reader waiting for a future offset is not behind
+				lag = 0
+			}
+			lags = append(lags, int(lag))
+		}
+	})
+	return lags
+}
+
+func (p pool[T]) TrimEvents() <-chan TrimEvent {
+	return p.trimEvents
+}
+
+func (p pool[T]) Stats() PoolStats {
+	var stats PoolStats
+	if !p.query(func(data *offsetData[T]) {
+		stats = p.currentStats(data)
+	}) {
+		return *p.stats
+	}
+	return stats
+}
+
+func (p pool[T]) Contains(offset int64) bool {
+	var ok bool
+	p.query(func(data *offsetData[T]) {
+		ok = data.IndexOf(offset) >= 0 && !data.IsRemoved(offset)
+	})
+	return ok
+}
+
+func (p pool[T]) IndexFor(offset int64) (int, bool) {
+	index := -1
+	p.query(func(data *offsetData[T]) {
+		if !data.IsRemoved(offset) {
+			index = data.IndexOf(offset)
+		}
+	})
+	return index, index >= 0
+}
+
+func (p pool[T]) TryRead(offset int64) (T, bool) {
+	var t T
+	var ok bool
+	p.query(func(data *offsetData[T]) {
+		if offset < 0 {
+			offset = resolveOffset(data, offset)
+		}
+		if i := data.IndexOf(offset); i >= 0 && !data.IsRemoved(offset) {
+			t, ok = data.at(i), true
+		}
+	})
+	return t, ok
+}
+
+func (p pool[T]) Len() int {
+	var l int
+	p.query(func(data *offsetData[T]) {
+		l = data.LiveLength()
+	})
+	return l
+}
+
+func (p pool[T]) FirstOffset() int64 {
+	var off int64
+	p.query(func(data *offsetData[T]) {
+		off = data.Offset()
+	})
+	return off
+}
+
+func (p pool[T]) NextOffset() int64 {
+	var off int64
+	p.query(func(data *offsetData[T]) {
+		off = data.NextOffset()
+	})
+	return off
+}
+
+func (p pool[T]) Snapshot() []T {
+	var snap []T
+	p.query(func(data *offsetData[T]) {
+		snap = data.LiveFrom(data.Offset())
+	})
+	return snap
+}
+
+func (p pool[T]) ReadWindow(from, to int64) ([]T, error) {
+	var window []T
+	var err error
+	if !p.query(func(data *offsetData[T]) {
+		if from >= to {
+			return
+		}
+		if to <= data.Offset() {
+			err = fmt.Errorf("%w: window ends at %d, first available is %d", ErrOffsetEvicted, to, data.Offset())
+			return
+		}
+		if from < data.Offset() {
+			from = data.Offset()
+		}
+		if to > data.NextOffset() {
+			to = data.NextOffset()
+		}
+		for offset := from; offset < to; offset++ {
+			if t, ok := data.Get(offset); ok {
+				window = append(window, t)
+			}
+		}
+	}) {
+		return nil, fmt.Errorf("window not read as %w", ErrPoolClosed)
+	}
+	return window, err
+}
+
+func (p pool[T]) MarshalSnapshot() ([]byte, error) {
+	var snap snapshot[T]
+	if !p.query(func(data *offsetData[T]) {
+		snap = snapshot[T]{Offset: data.Offset(), Data: data.LiveFrom(data.Offset())}
+	}) {
+		return nil, fmt.Errorf("snapshot not taken as %w", ErrPoolClosed)
+	}
+	return json.Marshal(snap)
+}
+
+func (p pool[T]) Flush() {
+	p.query(func(data *offsetData[T]) {
+		p.stats.Evicted += data.LiveLength()
+		*p.trimmed = TrimEvent{}
+		data.TrimToLength(0)
+		p.sendTrimEvent()
+		p.firstOffset.Store(data.Offset())
+	})
+}
+
+func (p pool[T]) Reset(ctx context.Context, items ...T) error {
+	done := make(chan struct{})
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("reset failed as %w", ctx.Err())
+	case <-p.done:
+		return fmt.Errorf("reset failed as %w", ErrPoolClosed)
+	case p.commands <- func(data *offsetData[T]) {
+		defer close(done)
+		p.stats.Evicted += data.LiveLength()
+		*p.trimmed = TrimEvent{}
+		data.Reset(0)
+		p.sendTrimEvent()
+		p.stats.Fed += len(items)
+		for _, t := range items {
+			p.appendElement(data, t, data.sizeOf(t))
+		}
+		// the offsets of active readers now refer to the old data, which each learns of as it next requests more
+		*p.resets++
+		if p.queue != nil {
+			p.queue.reset(0)
+		}
+		p.applyPolicy(data)
+		// waiting readers are woken to find they have been reset
+		p.releaseWaitLock()
+	}:
+	}
+	// once sent, the reset is run by the pool regardless of the context
+	<-done
+	return nil
+}
+
+func (p pool[T]) WaitForData(ctx context.Context, offset int64) error {
+	return p.waitFor(ctx, func(data *offsetData[T]) bool {
+		if offset < 0 {
+			offset = resolveOffset(data, offset)
+		}
+		return data.Length() > 0 && data.NextOffset() > offset
+	})
+}
+
+func (p pool[T]) WaitForOffset(ctx context.Context, offset int64) error {
+	return p.waitFor(ctx, func(data *offsetData[T]) bool {
+		return data.NextOffset() > offset
+	})
 }
 
-func (p pool[T]) submitRequest(rq request[T]) {
+func (p pool[T]) Ping(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("ping failed as %w", ctx.Err())
+	case <-p.done:
+		return fmt.Errorf("ping failed as %w", ErrPoolClosed)
+	case p.commands <- func(data *offsetData[T]) {
+		close(ack)
+	}:
+	}
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("ping failed as %w", ctx.Err())
+	case <-ack:
+		return nil
+	}
+}
+
+func (p pool[T]) Quiesce(ctx context.Context) error {
+	ticker := time.NewTicker(quiescePoll)
+	defer ticker.Stop()
+	for {
+		var idle bool
+		if !p.query(func(data *offsetData[T]) {
+			idle = p.feeding.Load() == 0 && len(p.requests) == 0 && len(p.priorityRequests) == 0 && p.isDrained(data)
+		}) {
+			return fmt.Errorf("quiesce failed as %w", ErrPoolClosed)
+		}
+		if idle {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.done:
+			return fmt.Errorf("quiesce failed as %w", ErrPoolClosed)
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitFor blocks until the given condition is true, checking it on the pool thread each time new data is fed.
+// An error is returned if the context is cancelled or the pool shuts down before then.
+func (p pool[T]) waitFor(ctx context.Context, cond func(data *offsetData[T]) bool) error {
+	for {
+		var wait chan struct{}
+		if !p.query(func(data *offsetData[T]) {
+			if !cond(data) {
+				wait = p.getWaitLock()
+			}
+		}) {
+			return fmt.Errorf("wait failed as %w", ErrPoolClosed)
+		}
+		if wait == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.done:
+			return fmt.Errorf("wait failed as %w", ErrPoolClosed)
+		case <-wait:
+		}
+	}
+}
+
+// query runs the given function on the pool thread, blocking until it has completed.
+// false is returned if the pool has shutdown and the function was not run.
+func (p pool[T]) query(fn func(data *offsetData[T])) bool {
+	done := make(chan struct{})
+	cmd := func(data *offsetData[T]) {
+		defer close(done)
+		fn(data)
+	}
+	select {
+	case <-p.done:
+		return false
+	case p.commands <- cmd:
+	}
+	<-done
+	return true
+}
+
+// submitRequest sends the request to the pool thread.
+// An error is returned if the pool has shutdown.
+func (p pool[T]) submitRequest(rq request[T]) error {
+	select {
+	case <-p.done:
+		return errAbortedByShutdown
+	default:
+	}
 	select {
 	case <-rq.Context().Done():
-		return
+		return nil
 	case <-p.done:
-		log.Println("submit aborted, pool closed")
-		rq.PostError() <- fmt.Errorf("request aborted as Pool has shutdown")
-		return
-	case p.requests <- rq:
-		return
+		return errAbortedByShutdown
+	case p.requestQueue(rq) <- rq:
+		return nil
+	}
+}
+
+// requestQueue returns the queue the given request is submitted to, according to its priority.
+func (p pool[T]) requestQueue(rq request[T]) chan request[T] {
+	if rq.Priority() > 0 {
+		return p.priorityRequests
 	}
+	return p.requests
 }
 
 func (p pool[T]) runPool(ctx context.Context, data *offsetData[T]) {
-	log.Println("pool is starting...")
-	defer close(p.requests)
-	defer close(p.feed)
-	defer close(p.done) // done should be first to close, which shuts down all Readers / Waiters, avoiding attempts to write to feed after its closed.
+	p.logger.Println("pool is starting...")
+	expiry := time.NewTimer(0)
+	defer expiry.Stop()
+	p.applyPolicy(data)
+	p.resetExpiry(expiry, data)
+	if p.onClose != nil {
+		defer func() {
+			p.onClose(*p.stats)
+		}()
+	}
+	// feed and requests are left open, closing done shuts down all Readers / Waiters / Feeders,
+	// so late arrivals can never send on a closed channel.
+	defer p.abortRequests()
+	defer close(p.trimEvents)
+	defer close(p.done)
 
 	defer func(data *offsetData[T]) {
-		log.Printf("Pool shutting down with %d elements in data\n", data.Length())
+		*p.stats = p.currentStats(data)
+		p.stats.Readers = 0
+		p.logger.Printf("Pool shutting down with %d elements in data\n", data.Length())
+		// evict the remaining elements, so they are passed to any OnEvict, and counted in the final stats
+		p.stats.Evicted += data.Length()
+		data.TrimToLength(0)
 	}(data)
 
+	var limiter rateLimiter
+	throttle := time.NewTimer(0)
+	defer throttle.Stop()
+
+	idle := time.NewTimer(0)
+	defer idle.Stop()
+	p.resetIdle(idle)
+
+	draining := p.draining
+	fedAhead := false    // a feed was taken ahead of the main select, on the last pass
+	var pending batch[T] // the rest of a batch, waiting for room to be made for each element
 	for {
+		if len(pending.items) > 0 {
+			// the rest of a batch is fed as room is made, ahead of any other feed, even while draining
+			pending = p.feedSlice(data, &limiter, pending)
+			p.resetExpiry(expiry, data)
+		}
+		feed, feedBatch := p.feed, p.feedBatch
+		if len(pending.items) > 0 || p.isFull(data) || draining == nil || p.isThrottled(&limiter, throttle) {
+			// stop accepting feeds until space is made or the rate allows, or for good once draining
+			feed, feedBatch = nil, nil
+		}
+		if draining == nil && len(pending.items) == 0 && p.isDrained(data) {
+			return
+		}
+		select {
+		case rq := <-p.priorityRequests:
+			// priority requests are serviced ahead of any other waiting work
+			rq.Respond(p.serviceRequest(data, rq))
+			p.resetIdle(idle)
+			continue
+		default:
+		}
+		if !fedAhead {
+			// a waiting feed is taken ahead of the resubmitted requests of busy readers, on alternate passes,
+			// so ingestion is not starved by reads, nor reads by a busy feeder
+			select {
+			case t := <-feed:
+				p.feeding.Add(-1)
+				p.feedElements(data, &limiter, t)
+				p.resetExpiry(expiry, data)
+				p.resetIdle(idle)
+				fedAhead = true
+				continue
+			case batch := <-feedBatch:
+				pending = p.feedSlice(data, &limiter, batch)
+				p.resetExpiry(expiry, data)
+				p.resetIdle(idle)
+				fedAhead = true
+				continue
+			default:
+			}
+		}
+		fedAhead = false
 		select {
 		case <-ctx.Done():
 			return
 
-		case t := <-p.feed:
-			data.Append(t)
-			p.releaseWaitLock()
+		case <-p.closing:
+			return
+
+		case <-draining:
+			p.logger.Println("pool is draining...")
+			draining = nil
+
+		case t := <-feed:
+			p.feeding.Add(-1)
+			p.feedElements(data, &limiter, t)
+			p.resetExpiry(expiry, data)
+			p.resetIdle(idle)
+
+		case batch := <-feedBatch:
+			pending = p.feedSlice(data, &limiter, batch)
+			p.resetExpiry(expiry, data)
+			p.resetIdle(idle)
+
+		case cmd := <-p.commands:
+			cmd(data)
+			// commands may change the data or policy
+			p.resetExpiry(expiry, data)
+			p.resetIdle(idle)
+
+		case <-throttle.C:
+			// the rate allows another element, checked as the loop repeats
+
+		case <-expiry.C:
+			p.applyPolicy(data)
+			p.resetExpiry(expiry, data)
+
+		case <-idle.C:
+			if len(p.readers) > 0 {
+				// attached readers keep the pool active, even while they wait for data
+				p.resetIdle(idle)
+				continue
+			}
+			p.logger.Printf("pool is closing after being idle for %v\n", p.policy.IdleTimeout)
+			return
+
+		case rq := <-p.priorityRequests:
+			rq.Respond(p.serviceRequest(data, rq))
+			p.resetIdle(idle)
 
 		case rq := <-p.requests:
-			rqOff := rq.Offset()
-			if rqOff < 0 {
-				rqOff = data.Offset()
-				// request with neg offset treated as requesting first available.
-				rq.ResetOffset(rqOff)
+			rq.Respond(p.serviceRequest(data, rq))
+			p.resetIdle(idle)
+		}
+	}
+}
+
+// serviceRequest builds the response to the given request, from the current data.
+func (p *pool[T]) serviceRequest(data *offsetData[T], rq request[T]) response[T] {
+	if p.queue != nil {
+		return p.serviceQueueRequest(data, rq)
+	}
+	rqOff := rq.Offset()
+	if rqOff < 0 {
+		rqOff = resolveOffset(data, rqOff)
+		rq.ResetOffset(rqOff)
+		if _, ok := p.readerResets[rq]; ok {
+			// resolved against the current data, whether or not the pool was reset since the read was made
+			p.readerResets[rq] = *p.resets
+			p.readerStarts[rq] = readerStart{offset: rqOff, at: p.readerStarts[rq].at}
+		}
+	}
+	if resets, ok := p.readerResets[rq]; ok && resets != *p.resets {
+		return p.serviceResetRequest(data, rq, rqOff)
+	}
+	if _, ok := p.readers[rq]; ok {
+		p.readers[rq] = rqOff
+	}
+	if rq.ReadCount() > 0 {
+		// the request has read every offset from where it started, or last jumped to, up to the one it now requests
+		data.MarkConsumed(rqOff-int64(rq.ReadCount()), rqOff)
+		p.applyPolicy(data)
+	}
+	if rq.IsComplete() {
+		return response[T]{complete: true}
+	}
+
+	if rqOff < data.Offset() && rq.Gaps() != nil {
+		// jump the gap to the first available
+		gap := GapEvent{MissedFrom: rqOff, MissedTo: data.Offset()}
+		rq.ResetOffset(data.Offset())
+		return response[T]{gap: &gap}
+	}
+	if rqOff < data.Offset() {
+		// offset already removed by policy
+		return response[T]{err: fmt.Errorf("%w: offset %d, first available is %d", ErrOffsetEvicted, rqOff, data.Offset())}
+	}
+	if data.LengthFrom(rqOff) == 0 {
+		// nothing to give, wait for new data
+		return response[T]{wait: p.getWaitLock()}
+	}
+	slice := data.SliceFrom(rqOff)
+	// PostData limits delivery to the remaining count, trimming here avoids handing out more than needed
+	if r := rq.Remaining(); r >= 0 && r < len(slice) {
+		slice = slice[:r]
+	}
+	limit := maxPostBatch
+	if b := rq.BatchSize(); b > 0 {
+		// a batch reader is given a single batch per turn
+		limit = b
+	}
+	if len(slice) > limit {
+		slice = slice[:limit]
+	}
+	return response[T]{data: slice, removed: data.RemovedFrom(rqOff, len(slice))}
+}
+
+// serviceResetRequest builds the response to a request whose offset was resolved before the pool was last reset, so no
+// longer refers to the same element. A gap tolerant request jumps to the first available offset, any other is ended.
+func (p *pool[T]) serviceResetRequest(data *offsetData[T], rq request[T], rqOff int64) response[T] {
+	p.readerResets[rq] = *p.resets
+	if rq.Gaps() == nil {
+		return response[T]{err: fmt.Errorf("%w: offset %d was removed as the pool was reset", ErrOffsetEvicted, rqOff)}
+	}
+	gap := GapEvent{MissedFrom: rqOff, MissedTo: data.Offset(), Reset: true}
+	rq.ResetOffset(data.Offset())
+	p.readers[rq] = data.Offset()
+	// the reader starts again with the new data
+	p.readerStarts[rq] = readerStart{offset: data.Offset(), at: p.readerStarts[rq].at}
+	return response[T]{gap: &gap}
+}
+
+// serviceQueueRequest builds the response to a request of a queue, handing it the next element not yet handed to
+// any other request, regardless of the offset of the request.
+// The element last handed to the request is only consumed once the request returns, having received it.
+func (p *pool[T]) serviceQueueRequest(data *offsetData[T], rq request[T]) response[T] {
+	p.queue.acknowledge(rq)
+	p.consumeQueue(data)
+	if _, ok := p.readers[rq]; !ok || rq.IsComplete() {
+		// a request resubmitted as its reader ended is handed nothing, as the reader would never receive it
+		return response[T]{complete: true}
+	}
+	offset, ok := p.queue.take(data, rq)
+	if !ok {
+		p.readers[rq] = data.NextOffset()
+		return response[T]{wait: p.getWaitLock()}
+	}
+	// recorded before handing out, so the reader only counts as drained once it returns having delivered
+	p.readers[rq] = offset
+	t, _ := data.Get(offset)
+	return response[T]{data: []T{t}}
+}
+
+// consumeQueue marks the elements every reader of a queue has received as consumed, applying the policy to them.
+func (p *pool[T]) consumeQueue(data *offsetData[T]) {
+	data.MarkConsumed(data.Offset(), p.queue.consumed())
+	p.applyPolicy(data)
+}
+
+// abortRequests ends any requests remaining in the request queue, once the pool has shutdown.
+func (p pool[T]) abortRequests() {
+	for {
+		select {
+		case rq := <-p.priorityRequests:
+			rq.Respond(response[T]{err: errAbortedByShutdown})
+		case rq := <-p.requests:
+			rq.Respond(response[T]{err: errAbortedByShutdown})
+		default:
+			return
+		}
+	}
+}
+
+// feedElements adds the fed elements to the data, applying the policy once they have all been added,
+// so the elements of a batch are held together, in order.
+// It returns the number of elements added, those rejected by the policy not being counted.
+func (p *pool[T]) feedElements(data *offsetData[T], limiter *rateLimiter, ts ...T) int {
+	var added int
+	now := p.now()
+	for _, t := range ts {
+		if p.skipZero && isZero(t) {
+			continue
+		}
+		p.stats.Fed++
+		size := data.sizeOf(t)
+		if p.isOversized(size) {
+			// an element larger than the policy Size could only be held by evicting everything, itself included
+			p.logger.Printf("element of %d bytes rejected as larger than policy Size %d\n", size, p.policy.Size)
+			p.reject(t)
+			continue
+		}
+		if p.isHoldingNewest(data) {
+			// rejected before being appended, so its offset is taken by the next element accepted
+			p.reject(t)
+			continue
+		}
+		if rate := p.policy.MaxRatePerSec; rate > 0 && !limiter.Take(rate, now) {
+			if p.policy.Overflow == OverflowDropNewest {
+				// elements beyond the rate are rejected
+				p.reject(t)
+				continue
 			}
+			// the rest of a batch is taken on credit, so following feeds wait for the rate to allow them
+			limiter.Spend()
+		}
+		p.appendElement(data, t, size)
+		added++
+	}
+	if added == 0 {
+		return 0
+	}
+	p.applyPolicy(data)
+	p.releaseWaitLock()
+	return added
+}
 
-			if data.LengthFrom(rqOff) == 0 {
-				// nothing to give, wait for new data
-				go p.waitAndResubmit(rq, p.getWaitLock())
-			} else {
-				go p.postAndResubmit(rq, data.SliceFrom(rqOff))
+// feedSlice feeds the batch, returning the rest of it left waiting for room to be made.
+// Under an OverflowBlock policy with a Count, elements are fed only while the pool has room, so a batch never takes
+// the pool beyond its Count. The batch remains counted as in flight until every element is fed.
+func (p *pool[T]) feedSlice(data *offsetData[T], limiter *rateLimiter, b batch[T]) batch[T] {
+	var added int
+	if p.policy.Overflow == OverflowBlock && p.policy.Count > 0 {
+		for len(b.items) > 0 && !p.isFull(data) {
+			added += p.feedElements(data, limiter, b.items[0])
+			b.items = b.items[1:]
+		}
+	} else {
+		added = p.feedElements(data, limiter, b.items...)
+		b.items = nil
+	}
+	if b.accepted != nil {
+		b.accepted.Add(int64(added))
+	}
+	if len(b.items) > 0 {
+		return b
+	}
+	p.feeding.Add(-1)
+	return batch[T]{}
+}
+
+// batch is a slice of elements fed together, with an optional count of those the pool adds.
+type batch[T any] struct {
+	items    []T
+	accepted *atomic.Int64 // when not nil, incremented by the pool thread with each element it adds
+}
+
+// isOversized checks if an element of the given byte size alone exceeds the policy Size, so can never be held.
+// A pool with a RetentionPolicy leaves the decision to the RetentionPolicy.
+func (p *pool[T]) isOversized(size uint64) bool {
+	return p.retention == nil && p.policy.Size > 0 && size > p.policy.Size
+}
+
+// appendElement appends the element, of the given byte size, to the data.
+// In a keyed pool, any earlier element with the same key is first removed.
+func (p *pool[T]) appendElement(data *offsetData[T], t T, size uint64) {
+	if p.keyOf != nil {
+		if key := p.keyOf(t); key != (noKey{}) {
+			if offset, ok := p.keys[key]; ok {
+				// the replaced element is counted as evicted, as if removed by the policy
+				data.Remove(offset)
+				p.stats.Evicted++
 			}
+			p.keys[key] = data.NextOffset()
 		}
 	}
+	data.AppendSized(t, size)
+}
+
+// onRemoved records each element removed from the data, in the current trim, and in a keyed pool, drops its key.
+func (p *pool[T]) onRemoved(offset int64, t T) {
+	p.trimmed.record(offset)
+	if p.keyOf != nil {
+		p.forgetKey(offset, t)
+	}
+}
+
+// forgetKey drops the key of an element removed from a keyed pool, unless a later element with the same key is held.
+func (p *pool[T]) forgetKey(offset int64, t T) {
+	key := p.keyOf(t)
+	if key == (noKey{}) {
+		return
+	}
+	if p.keys[key] == offset {
+		delete(p.keys, key)
+	}
+}
+
+// isZero checks if the given element is the zero value of its type, such as a nil pointer.
+func isZero[T any](t T) bool {
+	return reflect.ValueOf(&t).Elem().IsZero()
+}
+
+// resolveOffset converts a negative, relative offset, into an absolute offset in the given data.
+func resolveOffset[T any](data *offsetData[T], offset int64) int64 {
+	if offset == ReadLatest {
+		return data.NextOffset()
+	}
+	if offset == -1 {
+		// first available
+		return data.Offset()
+	}
+	// -1-n is n elements back from the end, clamped to the first available
+	n := -1 - offset
+	if off := data.NextOffset() - n; off > data.Offset() {
+		return off
+	}
+	return data.Offset()
+}
+
+// sendTrimEvent reports the elements removed by the current trim, if any, to the TrimEvents channel.
+func (p *pool[T]) sendTrimEvent() {
+	if p.trimmed.Count == 0 {
+		return
+	}
+	select {
+	case p.trimEvents <- *p.trimmed:
+	default:
+		// no one is listening, or keeping up, so the event is dropped
+	}
 }
 
 func (p *pool[T]) applyPolicy(data *offsetData[T]) {
+	*p.trimmed = TrimEvent{}
+	defer func(length int) {
+		p.stats.Evicted += length - data.LiveLength()
+		p.firstOffset.Store(data.Offset())
+		p.sendTrimEvent()
+	}(data.LiveLength())
+
+	if p.overflowSink != nil {
+		data.onUnread = p.sendOverflow
+		defer func() {
+			data.onUnread = nil
+		}()
+	}
+	if p.retention != nil {
+		p.retention.Evict(data)
+		return
+	}
+	if p.policy.EvictConsumed {
+		// with no readers, or only those yet to learn of a reset, nothing is removed
+		if consumed := p.consumedByAll(data); consumed > data.Offset() {
+			data.TrimToLength(int(data.NextOffset() - consumed))
+		}
+	}
+	if p.policy.MaxAge > 0 {
+		data.TrimToAge(p.now().Add(-p.policy.MaxAge))
+	}
 	if p.policy.Size > 0 && p.policy.Size < data.Size() {
 		data.TrimToSize(p.policy.Size)
 	}
-	if p.policy.Count > 0 && p.policy.Count < data.Length() {
-		data.TrimToLength(p.policy.Count)
+	if p.policy.Count > 0 && p.policy.Count < data.LiveLength() {
+		count := p.policy.Count
+		if p.policy.Overflow == OverflowDropNewest {
+			// fed elements are rejected once the Count is held, so only initial data or a lowered Count is truncated
+			data.TruncateToLength(count)
+			return
+		}
+		if p.policy.Overflow == OverflowBlock {
+			// only elements already read may be removed
+			if unread := data.Length() - data.ConsumedLength(); unread > count {
+				count = unread
+			}
+		}
+		data.TrimToLive(count)
 	}
 }
 
-func (p *pool[T]) getWaitLock() chan struct{} {
-	p.waitLockMutex.Lock()
-	defer p.waitLockMutex.Unlock()
+// consumedByAll returns the offset below which every element held has been read by every active reader, being the
+// lowest offset requested, or the first offset held when there are no readers, or the elements have not all been read.
+// Readers yet to learn the pool was reset are passed over, their offsets predating the reset. A reader only reads from
+// where it started, so while any reader started beyond the first element held, no element has been read by every reader.
+func (p *pool[T]) consumedByAll(data *offsetData[T]) int64 {
+	consumed := int64(math.MaxInt64)
+	for rq, offset := range p.readers {
+		if p.readerResets[rq] != *p.resets {
+			continue
+		}
+		if p.readerStarts[rq].offset > data.Offset() {
+			return data.Offset()
+		}
+		if offset < consumed {
+			consumed = offset
+		}
+	}
+	if consumed == math.MaxInt64 {
+		return data.Offset()
+	}
+	return consumed
+}
+
+// currentStats returns the stats of the pool, with the current state of the given data.
+func (p *pool[T]) currentStats(data *offsetData[T]) PoolStats {
+	stats := *p.stats
+	stats.Length = data.LiveLength()
+	stats.Delivered = int(p.delivered.Load())
+	stats.FirstOffset = data.Offset()
+	stats.NextOffset = data.NextOffset()
+	stats.Readers = len(p.readers)
+	stats.Resets = *p.resets
+	return stats
+}
 
-	if p.waitLock == nil {
-		p.waitLock = make(chan struct{})
+// sendOverflow sends an unread element, removed by the policy, to the overflow sink.
+// It never waits on the sink, as it runs on the pool thread. An element the sink can not take is dropped and counted.
+func (p *pool[T]) sendOverflow(t T) {
+	select {
+	case p.overflowSink <- t:
+	default:
+		p.stats.SinkDropped++
 	}
-	return p.waitLock
 }
 
-func (p *pool[T]) releaseWaitLock() {
-	p.waitLockMutex.Lock()
-	defer p.waitLockMutex.Unlock()
-	if p.waitLock != nil {
-		close(p.waitLock)
-		p.waitLock = nil
+// reject counts an element the policy refuses to accept as evicted, passing it to any overflow sink, as it is unread.
+func (p *pool[T]) reject(t T) {
+	p.stats.Evicted++
+	if p.overflowSink != nil {
+		p.sendOverflow(t)
 	}
 }
 
-// methods below are called outside the main pool thread.
-func (p *pool[T]) waitAndResubmit(rq request[T], waitLock chan struct{}) {
-	select {
-	case <-rq.Context().Done():
-		return
-	case <-p.done:
-		rq.PostError() <- fmt.Errorf("waiting request aborted, pool has shutdown")
+// isHoldingNewest checks if the pool rejects new elements, as it holds its Count with an OverflowDropNewest policy.
+// A pool with a RetentionPolicy leaves the decision to the RetentionPolicy.
+func (p *pool[T]) isHoldingNewest(data *offsetData[T]) bool {
+	return p.retention == nil && p.policy.Overflow == OverflowDropNewest && p.policy.Count > 0 &&
+		data.LiveLength() >= p.policy.Count
+}
+
+// isDrained checks if every active reader has requested the offset following the last element.
+func (p *pool[T]) isDrained(data *offsetData[T]) bool {
+	for _, offset := range p.readers {
+		if offset < data.NextOffset() {
+			return false
+		}
+	}
+	return true
+}
+
+// isThrottled checks if feeds must wait for the policy MaxRatePerSec to allow another element, setting the throttle
+// timer to fire once it does. A pool with an OverflowDropNewest policy is never throttled, rejecting elements instead.
+func (p *pool[T]) isThrottled(limiter *rateLimiter, throttle *time.Timer) bool {
+	rate := p.policy.MaxRatePerSec
+	if rate <= 0 || p.policy.Overflow == OverflowDropNewest || limiter.Allow(rate, p.now()) {
+		return false
+	}
+	if !throttle.Stop() {
+		select {
+		case <-throttle.C:
+		default:
+		}
+	}
+	throttle.Reset(limiter.Delay(rate))
+	return true
+}
+
+// isFull checks if the pool is blocking new feeds, as it holds its Count and has no read elements to remove.
+func (p *pool[T]) isFull(data *offsetData[T]) bool {
+	return p.policy.Overflow == OverflowBlock && p.policy.Count > 0 &&
+		data.Length() >= p.policy.Count && data.ConsumedLength() == 0
+}
+
+// resetExpiry sets the timer to fire when the oldest element in the data exceeds the policy MaxAge.
+// If the policy has no MaxAge, the timer is left stopped.
+func (p *pool[T]) resetExpiry(timer *time.Timer, data *offsetData[T]) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	if p.policy.MaxAge <= 0 {
 		return
-	case <-waitLock:
-		p.submitRequest(rq)
 	}
+	d := p.policy.MaxAge
+	if oldest, ok := data.OldestTime(); ok {
+		d = oldest.Add(p.policy.MaxAge).Sub(p.now())
+	}
+	timer.Reset(d)
 }
 
-func (p pool[T]) postAndResubmit(rq request[T], data []T) {
-	rq.PostData(data)
-	p.submitRequest(rq)
+// resetIdle restarts the timer to fire once the pool has been idle for the policy IdleTimeout.
+// If the policy has no IdleTimeout, the timer is left stopped.
+func (p *pool[T]) resetIdle(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	if p.policy.IdleTimeout > 0 {
+		timer.Reset(p.policy.IdleTimeout)
+	}
 }
 
-func (p *pool[T]) waitAndPurge(rq request[T], waitLock chan struct{}) {
+func (p *pool[T]) getWaitLock() chan struct{} {
+	p.waitLock.mu.Lock()
+	defer p.waitLock.mu.Unlock()
+
+	if p.waitLock.ch == nil {
+		p.waitLock.ch = make(chan struct{})
+	}
+	return p.waitLock.ch
+}
+
+func (p *pool[T]) releaseWaitLock() {
+	p.waitLock.mu.Lock()
+	defer p.waitLock.mu.Unlock()
+	if p.waitLock.ch != nil {
+		close(p.waitLock.ch)
+		p.waitLock.ch = nil
+	}
+}
+
+// methods below are called outside the main pool thread.
+
+// serveRequest services a request for the life of its reader, repeatedly submitting it to the pool thread and
+// acting on each response, until the request is complete, its context is cancelled, or an error ends it.
+func (p pool[T]) serveRequest(rq request[T]) error {
+	for {
+		if err := p.submitRequest(rq); err != nil {
+			return err
+		}
+		var resp response[T]
+		select {
+		case <-rq.Context().Done():
+			return nil
+		case <-p.done:
+			return errAbortedByShutdown
+		case resp = <-rq.Response():
+		}
+
+		switch {
+		case resp.err != nil:
+			return resp.err
+		case resp.complete:
+			return nil
+		case resp.gap != nil:
+			select {
+			case <-rq.Context().Done():
+				return nil
+			case <-p.done:
+				return errAbortedByShutdown
+			case rq.Gaps() <- *resp.gap:
+			}
+		case resp.wait != nil:
+			if err := p.waitAndPurge(rq, resp.wait); err != nil {
+				return err
+			}
+		default:
+			// once delivered, the request is resubmitted, even when complete, to report its progress to the pool
+			p.delivered.Add(int64(rq.PostData(resp.data, resp.removed)))
+		}
+	}
+}
+
+// waitAndPurge waits for new data to be fed, before the request is resubmitted.
+// If, by the time new data arrives, the requested offset has already been evicted, ErrOffsetEvicted is returned,
+// unless the request tolerates gaps, in which case it is resubmitted to jump the gap.
+func (p pool[T]) waitAndPurge(rq request[T], waitLock chan struct{}) error {
 	select {
 	case <-rq.Context().Done():
-		return
+		return nil
 	case <-p.done:
-		rq.PostError() <- fmt.Errorf("waiting request aborted, pool has shutdown")
-		return
+		return errAbortedByShutdown
 	case <-waitLock:
-		p.submitRequest(rq)
+		if first := p.firstOffset.Load(); rq.Offset() < first && rq.Gaps() == nil && p.queue == nil {
+			return fmt.Errorf("%w: offset %d, first available is %d", ErrOffsetEvicted, rq.Offset(), first)
+		}
+		return nil
 	}
 }
 
 // NewPool creates a new Pool containing any given data.
 // The Pool will be returned in an active state, ready to receive new data or Read any given data.
 // It will remain active until the given context is cancelled.
-func NewPool[T any](ctx context.Context, policy Policy, data ...T) Pool[T] {
-	if !policy.IsConstrainded() {
-		log.Fatalln("policy is unconstrained. Pool can not have unlimited memory")
+// An error is returned if the policy is not valid.
+func NewPool[T any](ctx context.Context, policy Policy, data ...T) (Pool[T], error) {
+	return NewPoolWithOptions(ctx, policy, WithData(data...))
+}
+
+// MustNewPool creates a new Pool in the same way as NewPool, panicking if the pool can not be created.
+func MustNewPool[T any](ctx context.Context, policy Policy, data ...T) Pool[T] {
+	p, err := NewPool(ctx, policy, data...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// NewPoolCancelable creates a new Pool in the same way as NewPool, with a context of its own,
+// returning the function to cancel it and so shut down the pool.
+// An error is returned if the policy is not valid.
+func NewPoolCancelable[T any](policy Policy, data ...T) (Pool[T], context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p, err := NewPool(ctx, policy, data...)
+	if err != nil {
+		cancel()
+		return nil, nil, err
 	}
+	return p, cancel, nil
+}
+
+// NewPoolWithOptions creates a new Pool, configured with the given options.
+// As with NewPool, the Pool is returned in an active state and remains active until the given context is cancelled.
+func NewPoolWithOptions[T any](ctx context.Context, policy Policy, opts ...Option[T]) (Pool[T], error) {
 	p := &pool[T]{
-		feed:          make(chan T),
-		requests:      make(chan request[T], 10),
-		done:          make(chan struct{}),
-		policy:        policy,
-		waitLockMutex: &sync.Mutex{},
+		feed:             make(chan T),
+		feedBatch:        make(chan batch[T]),
+		feeding:          &atomic.Int64{},
+		requests:         make(chan request[T], defaultRequestBuffer),
+		priorityRequests: make(chan request[T], defaultRequestBuffer),
+		commands:         make(chan command[T]),
+		done:             make(chan struct{}),
+		closing:          make(chan struct{}),
+		cancelled:        ctx.Done(),
+		closeOnce:        &sync.Once{},
+		draining:         make(chan struct{}),
+		drainOnce:        &sync.Once{},
+		policy:           &policy,
+		waitLock:         &waitLock{},
+		now:              time.Now,
+		stats:            &PoolStats{},
+		delivered:        &atomic.Int64{},
+		firstOffset:      &atomic.Int64{},
+		fedChannels:      &sync.Map{},
+		readers:          map[request[T]]int64{},
+		readerStarts:     map[request[T]]readerStart{},
+		resets:           new(int),
+		readerResets:     map[request[T]]int{},
+		logger:           nopLogger{},
+		trimEvents:       make(chan TrimEvent, trimEventBuffer),
+		trimmed:          &TrimEvent{},
 	}
-	go p.runPool(ctx, &offsetData[T]{
-		data:   data,
-		offset: 0,
-	})
-	return p
+	for _, opt := range opts {
+		opt(p)
+	}
+	if err := p.validatePolicy(policy); err != nil {
+		return nil, err
+	}
+	p.guardCallbacks()
+	p.stats.Fed = len(p.data)
+	data := newOffsetData[T](p.offset, p.sizer, p.onEvict)
+	data.onRemove = p.onRemoved
+	data.now = p.now
+	if p.keyOf != nil {
+		// initial data is appended in turn, so only the latest of each key is kept
+		for _, t := range p.data {
+			p.appendElement(data, t, data.sizeOf(t))
+		}
+	} else {
+		data.Append(p.data...)
+	}
+	// released ahead of starting the pool thread, which runs on its own copy of the pool
+	p.data = nil
+	go p.runPool(ctx, data)
+	return p, nil
 }
@@ -0,0 +1,2609 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPolicy_CountAppliedAfterEachFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	ch := make(chan int)
+	p.Feed(ctx, ch)
+	for i := 0; i < 1000; i++ {
+		ch <- i
+		if n := p.Len(); n > 10 {
+			t.Fatalf("expected no more than 10 elements, found %d after feeding %d", n, i+1)
+		}
+	}
+	if err := p.WaitForOffset(ctx, 999); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Len(); n != 10 {
+		t.Fatalf("expected 10 elements, found %d", n)
+	}
+	if first := p.FirstOffset(); first != 990 {
+		t.Fatalf("expected first offset 990, found %d", first)
+	}
+	if v := <-p.Read(ctx, -1); v != 990 {
+		t.Fatalf("expected first element 990, found %d", v)
+	}
+}
+
+func TestPolicy_CountAppliedToInitialData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 2}, 1, 2, 3)
+	ch := p.Read(ctx, -1)
+	if v := <-ch; v != 2 {
+		t.Fatalf("expected first element 2, found %d", v)
+	}
+	if v := <-ch; v != 3 {
+		t.Fatalf("expected second element 3, found %d", v)
+	}
+}
+
+func TestPolicy_SizeWithSizer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var data []*poolTest
+	for _, name := range []string{"hello", "world", "how", "you", "doing"} {
+		data = append(data, &poolTest{Name: name})
+	}
+	p, err := NewPoolWithOptions[*poolTest](ctx, Policy{Size: 10},
+		WithSizer(func(pt *poolTest) uint64 { return uint64(len(pt.Name)) }), WithData(data...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := p.Read(ctx, -1)
+	for _, want := range []string{"you", "doing"} {
+		if pt := <-ch; pt.Name != want {
+			t.Fatalf("expected %q, found %q", want, pt.Name)
+		}
+	}
+}
+
+func TestReset_EvictConsumedKeepsReseededData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 100, EvictConsumed: true}, WithData(0, 1, 2, 3, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := p.Read(ctx, 0)
+	for i := 0; i < 5; i++ {
+		<-r
+	}
+	// once the reader has requested the next offset, every element is consumed
+	if err := p.Quiesce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Len(); n != 0 {
+		t.Fatalf("expected the consumed elements to be evicted, found %d", n)
+	}
+	if err := p.Reset(ctx, 10, 11, 12); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Len(); n != 3 {
+		t.Fatalf("expected reseeded elements to be kept, found %d", n)
+	}
+}
+
+type panicSizer struct{}
+
+func (panicSizer) PoolSize() uint64 {
+	panic("no size")
+}
+
+func TestCallbacks_PanicsAreRecovered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sized := MustNewPool[panicSizer](ctx, Policy{Size: 100}, panicSizer{})
+	if err := sized.Append(ctx, panicSizer{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := sized.Len(); n != 2 {
+		t.Fatalf("expected 2 elements, found %d", n)
+	}
+
+	keyed, err := NewKeyedPool[int, int](ctx, Policy{Count: 10}, func(i int) int {
+		if i < 0 {
+			panic("no key")
+		}
+		return i % 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{1, -1, 3, -3} {
+		if err := keyed.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 3 replaces 1, the elements without a key are both held
+	if all := keyed.Snapshot(); len(all) != 3 {
+		t.Fatalf("expected [-1 3 -3], found %v", all)
+	}
+}
+
+func TestCallbacks_BuiltInSizeNotGuarded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// only user code is guarded, the built in measure of an element is used directly
+	if p := MustNewPool[[]byte](ctx, Policy{Size: 100}).(*pool[[]byte]); p.sizer != nil {
+		t.Fatal("expected no sizer wrapping the built in measure of a byte slice")
+	}
+	if p := MustNewPool[panicSizer](ctx, Policy{Size: 100}).(*pool[panicSizer]); p.sizer == nil {
+		t.Fatal("expected the element's own Sizer to be guarded")
+	}
+}
+
+func TestRead_InitialDataVisibleFromFirst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 1, 2)
+	first := p.Read(ctx, -1)
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []int{1, 2, 3} {
+		if v := <-first; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+}
+
+func TestRead_TailSkipsInitialData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 1, 2)
+	tail := p.Read(ctx, ReadLatest)
+	from := p.ReadFrom(ctx, FromLatest)
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-tail; v != 3 {
+		t.Fatalf("expected the tail reader to start at 3, found %d", v)
+	}
+	if v := <-from; v != 3 {
+		t.Fatalf("expected the FromLatest reader to start at 3, found %d", v)
+	}
+}
+
+func TestReadN_BlocksUntilFed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	r := p.ReadN(ctx, 0, 5)
+	for i := 0; i < 3; i++ {
+		if v := <-r; v != i {
+			t.Fatalf("expected %d, found %d", i, v)
+		}
+	}
+	select {
+	case v, ok := <-r:
+		t.Fatalf("expected the read to wait for more elements, found %d, %v", v, ok)
+	case <-time.After(20 * time.Millisecond):
+	}
+	feed := make(chan int, 3)
+	for i := 3; i < 6; i++ {
+		feed <- i
+	}
+	p.Feed(ctx, feed)
+	for i := 3; i < 5; i++ {
+		if v := <-r; v != i {
+			t.Fatalf("expected %d, found %d", i, v)
+		}
+	}
+	if v, ok := <-r; ok {
+		t.Fatalf("expected the read to close after 5 elements, found %d", v)
+	}
+}
+
+func TestReadN_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	rctx, rcancel := context.WithCancel(ctx)
+	defer rcancel()
+	r := p.ReadN(rctx, 0, 100)
+	var got int
+	for range r {
+		got++
+		if got == 3 {
+			rcancel()
+		}
+	}
+	if got != 3 {
+		t.Fatalf("expected 3 elements, found %d", got)
+	}
+}
+
+func TestReadN_Complete(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	var got []int
+	for v := range p.ReadN(ctx, 1, 2) {
+		got = append(got, v)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected [1 2], found %v", got)
+	}
+}
+
+func TestRead_LatestOnlyReadsNewElements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make([]int, 10)
+	for i := range items {
+		items[i] = i
+	}
+	p := MustNewPool[int](ctx, Policy{Count: 100}, items...)
+	r := p.ReadN(ctx, ReadLatest, 3)
+	feed := make(chan int, 3)
+	for i := 10; i < 13; i++ {
+		feed <- i
+	}
+	p.Feed(ctx, feed)
+	var got []int
+	for v := range r {
+		got = append(got, v)
+	}
+	if len(got) != 3 || got[0] != 10 || got[2] != 12 {
+		t.Fatalf("expected [10 11 12], found %v", got)
+	}
+}
+
+func TestRead_LatestWaitsOnEmptyPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 100})
+	r := p.Read(ctx, ReadLatest)
+	select {
+	case v := <-r:
+		t.Fatalf("expected the read to wait, found %d", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+	feed := make(chan int, 1)
+	feed <- 1
+	p.Feed(ctx, feed)
+	if v := <-r; v != 1 {
+		t.Fatalf("expected 1, found %d", v)
+	}
+}
+
+// waitForFed waits until the pool has been fed n elements, in total.
+func waitForFed[T any](t *testing.T, p Pool[T], n int) {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for p.FirstOffset()+int64(p.Len()) < int64(n) {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d elements to be fed", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestLen_TracksFeedsAndEvictions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3})
+	if n := p.Len(); n != 0 {
+		t.Fatalf("expected an empty pool, found %d", n)
+	}
+	feed := make(chan int)
+	p.Feed(ctx, feed)
+	for i := 0; i < 5; i++ {
+		feed <- i
+		waitForFed(t, p, i+1)
+		want := i + 1
+		if want > 3 {
+			want = 3
+		}
+		if n := p.Len(); n != want {
+			t.Fatalf("expected %d elements, found %d", want, n)
+		}
+	}
+}
+
+func TestFirstOffset_AdvancesAsTrimmed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2)
+	if first := p.FirstOffset(); first != 0 {
+		t.Fatalf("expected first offset 0, found %d", first)
+	}
+	feed := make(chan int, 2)
+	feed <- 3
+	feed <- 4
+	p.Feed(ctx, feed)
+	waitForFed(t, p, 5)
+	if first := p.FirstOffset(); first != 2 {
+		t.Fatalf("expected first offset 2, found %d", first)
+	}
+}
+
+func TestRead_EvictedOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := &captureLogger{}
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 3}, WithData(0, 1, 2, 3, 4, 5), WithLogger[int](logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := <-p.Read(ctx, 1); ok {
+		t.Fatalf("expected the read to end, found %d", v)
+	}
+	if !logger.contains(ErrOffsetEvicted.Error()) {
+		t.Fatalf("expected the read to end with ErrOffsetEvicted, logged %q", logger.messages)
+	}
+}
+
+func TestRead_FutureOffsetWaits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2)
+	r := p.Read(ctx, 4)
+	select {
+	case v, ok := <-r:
+		t.Fatalf("expected the read to wait, found %d, %v", v, ok)
+	case <-time.After(20 * time.Millisecond):
+	}
+	feed := make(chan int, 2)
+	feed <- 3
+	feed <- 4
+	p.Feed(ctx, feed)
+	if v := <-r; v != 4 {
+		t.Fatalf("expected 4, found %d", v)
+	}
+}
+
+func TestClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 5}, 0, 1, 2)
+	r := p.Read(ctx, ReadLatest)
+	ch := make(chan int)
+	fed := p.Feed(ctx, ch)
+
+	p.Close()
+	p.Close()
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		p.WaitForClose()
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the pool to close")
+	}
+	if _, ok := <-r; ok {
+		t.Fatal("expected an active read to end")
+	}
+	<-fed
+
+	<-p.Feed(ctx, ch)
+	if _, ok := <-p.Read(ctx, 0); ok {
+		t.Fatal("expected a read of a closed pool to end")
+	}
+}
+
+func TestSnapshot_IsACopy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 5}, 0, 1, 2)
+	feed := make(chan int, 1)
+	feed <- 3
+	p.Feed(ctx, feed)
+	waitForFed(t, p, 4)
+	s := p.Snapshot()
+	if len(s) != 4 || s[3] != 3 {
+		t.Fatalf("expected [0 1 2 3], found %v", s)
+	}
+	s[0] = 100
+	if v := p.Snapshot()[0]; v != 0 {
+		t.Fatalf("expected the pool to be unaffected by the snapshot changing, found %d", v)
+	}
+	if v := <-p.ReadN(ctx, 0, 1); v != 0 {
+		t.Fatalf("expected a read to be unaffected by the snapshot changing, found %d", v)
+	}
+}
+
+func TestNewPool_UnconstrainedPolicy(t *testing.T) {
+	p, err := NewPool[int](context.Background(), Policy{})
+	if !errors.Is(err, ErrUnconstrainedPolicy) {
+		t.Fatalf("expected ErrUnconstrainedPolicy, found %v", err)
+	}
+	if p != nil {
+		t.Fatal("expected no pool to be returned")
+	}
+}
+
+func TestMustNewPool_PanicsOnError(t *testing.T) {
+	defer func() {
+		r := recover()
+		if err, ok := r.(error); !ok || !errors.Is(err, ErrUnconstrainedPolicy) {
+			t.Fatalf("expected a panic with ErrUnconstrainedPolicy, found %v", r)
+		}
+	}()
+	MustNewPool[int](context.Background(), Policy{})
+}
+
+func TestReadWithErr_Shutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0)
+	r, errc := p.ReadWithErr(ctx, 0)
+	if v := <-r; v != 0 {
+		t.Fatalf("expected 0, found %d", v)
+	}
+	// the read now waits for the next element
+	p.Close()
+	if v, ok := <-r; ok {
+		t.Fatalf("expected the read to end, found %d", v)
+	}
+	if err := <-errc; err == nil {
+		t.Fatal("expected the shutdown to end the read with an error")
+	}
+}
+
+func TestRead_SlowReaderDoesNotStarveOthers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	p := MustNewPool[int](ctx, Policy{Count: len(items)}, items...)
+	// the slow reader is left holding its delivery, so is given a context of its own which is never cancelled
+	slow := p.Read(context.Background(), 0)
+	<-slow
+
+	var wg sync.WaitGroup
+	counts := make([]int, 4)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for range p.ReadN(ctx, 0, len(items)) {
+				counts[i]++
+			}
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the fast readers to complete alongside the slow reader")
+	}
+	for i, n := range counts {
+		if n != len(items) {
+			t.Fatalf("expected reader %d to read %d elements, found %d", i, len(items), n)
+		}
+	}
+}
+
+func TestReadBuffered_ClosedOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	rctx, rcancel := context.WithCancel(ctx)
+	r := p.ReadBuffered(rctx, 0, 8)
+	if v := <-r; v != 0 {
+		t.Fatalf("expected 0, found %d", v)
+	}
+	rcancel()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-r:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected the buffered channel to be closed once the read was cancelled")
+		}
+	}
+}
+
+func benchmarkRead(b *testing.B, bufSize int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make([]int, 1024)
+	p := MustNewPool[int](ctx, Policy{Count: len(items)}, items...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rctx, rcancel := context.WithCancel(ctx)
+		r := p.ReadBuffered(rctx, 0, bufSize)
+		for n := 0; n < len(items); n++ {
+			<-r
+		}
+		rcancel()
+	}
+}
+
+func BenchmarkRead_Unbuffered(b *testing.B) {
+	benchmarkRead(b, 0)
+}
+
+func BenchmarkRead_Buffered(b *testing.B) {
+	benchmarkRead(b, 64)
+}
+
+func TestPolicy_EvictedElementsAreCollected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	collected := make(chan string, 20)
+	newElement := func(name string) *poolTest {
+		pt := &poolTest{Name: name}
+		runtime.SetFinalizer(pt, func(pt *poolTest) {
+			collected <- pt.Name
+		})
+		return pt
+	}
+	// the initial data is evicted too, so must not be held on to by the pool
+	p := MustNewPool[*poolTest](ctx, Policy{Count: 2}, newElement("initial"), newElement("initial"))
+	feed := make(chan *poolTest, 10)
+	for i := 0; i < 10; i++ {
+		feed <- newElement("fed")
+	}
+	close(feed)
+	p.Feed(ctx, feed)
+	waitForFed(t, p, 12)
+	if n := p.Len(); n != 2 {
+		t.Fatalf("expected 2 elements held, found %d", n)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for n := 0; n < 10; {
+		runtime.GC()
+		select {
+		case <-collected:
+			n++
+		case <-timeout:
+			t.Fatalf("expected all 10 evicted elements to be collected, found %d", n)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestFeedCounted_EarlyCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 100})
+	fctx, fcancel := context.WithCancel(ctx)
+	ch := make(chan int)
+	done, accepted := p.FeedCounted(fctx, ch)
+	for i := 0; i < 5; i++ {
+		ch <- i
+	}
+	fcancel()
+	<-done
+	if n := accepted(); n != p.Len() {
+		t.Fatalf("expected the accepted count to match the %d held, found %d", p.Len(), n)
+	}
+	if n := accepted(); n < 4 || n > 5 {
+		t.Fatalf("expected 4 or 5 accepted, found %d", n)
+	}
+}
+
+func TestFeedCounted_ClosedChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 100})
+	ch := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		ch <- i
+	}
+	close(ch)
+	done, accepted := p.FeedCounted(ctx, ch)
+	<-done
+	if n := accepted(); n != 10 {
+		t.Fatalf("expected 10 accepted, found %d", n)
+	}
+	if n := p.Len(); n != 10 {
+		t.Fatalf("expected 10 held, found %d", n)
+	}
+}
+
+func TestFeedCounted_RejectedNotCounted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 2, Overflow: OverflowDropNewest})
+	ch := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		ch <- i
+	}
+	close(ch)
+	done, accepted := p.FeedCounted(ctx, ch)
+	<-done
+	if n := accepted(); n != 2 {
+		t.Fatalf("expected only the 2 held to be accepted, found %d", n)
+	}
+	if n := p.Len(); n != 2 {
+		t.Fatalf("expected 2 held, found %d", n)
+	}
+}
+
+func TestFeedCounted_SkippedZeroNotCounted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithSkipZero[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan int, 4)
+	for _, v := range []int{0, 1, 0, 2} {
+		ch <- v
+	}
+	close(ch)
+	done, accepted := p.FeedCounted(ctx, ch)
+	<-done
+	if n := accepted(); n != 2 {
+		t.Fatalf("expected only the 2 non-zero elements to be accepted, found %d", n)
+	}
+	if n := p.Len(); n != 2 {
+		t.Fatalf("expected 2 held, found %d", n)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	if err := p.Append(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Snapshot(); len(s) != 1 || s[0] != 1 {
+		t.Fatalf("expected the pool to hold [1], found %v", s)
+	}
+
+	cctx, ccancel := context.WithCancel(ctx)
+	ccancel()
+	if err := p.Append(cctx, 2); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, found %v", err)
+	}
+
+	p.Close()
+	p.WaitForClose()
+	if err := p.Append(ctx, 3); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, found %v", err)
+	}
+}
+
+func TestNextOffset_CountsAcrossTrims(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1)
+	if next := p.NextOffset(); next != 2 {
+		t.Fatalf("expected next offset 2, found %d", next)
+	}
+	for i := 2; i < 20; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+		if next := p.NextOffset(); next != int64(i+1) {
+			t.Fatalf("expected next offset %d, found %d", i+1, next)
+		}
+	}
+	if first := p.FirstOffset(); first != 17 {
+		t.Fatalf("expected the pool to be trimmed to first offset 17, found %d", first)
+	}
+}
+
+func TestReadWithErr_WaitingOffsetEvicted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2)
+	r, errc := p.ReadWithErr(ctx, 3)
+	waitForWaitingRead[int](p)
+	// fed together, so offset 3 is evicted before the waiting reader is woken
+	if err := p.FeedSlice(ctx, []int{3, 4, 5, 6, 7, 8}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := <-r; ok {
+		t.Fatalf("expected the read to end, found %d", v)
+	}
+	if err := <-errc; !errors.Is(err, ErrOffsetEvicted) {
+		t.Fatalf("expected ErrOffsetEvicted, found %v", err)
+	}
+}
+
+func TestReadGapTolerant_LaggingReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2)
+	r, gaps := p.ReadGapTolerant(ctx, 0)
+	if v := <-r; v != 0 {
+		t.Fatalf("expected 0, found %d", v)
+	}
+	// the reader lags, holding 1 and 2, while the pool trims far beyond them
+	for i := 3; i < 10; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []int
+	var gap GapEvent
+	for len(got) < 5 {
+		select {
+		case v := <-r:
+			got = append(got, v)
+		case gap = <-gaps:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected the reader to continue past the gap, found %v", got)
+		}
+	}
+	// the elements of the delivery in hand are received, the next offset is then found evicted
+	if got[0] != 1 || got[1] != 2 || got[2] != 7 || got[3] != 8 || got[4] != 9 {
+		t.Fatalf("expected [1 2 7 8 9], found %v", got)
+	}
+	if gap.MissedFrom != 3 || gap.MissedTo != 7 {
+		t.Fatalf("expected a gap from 3 to 7, found %+v", gap)
+	}
+}
+
+func TestReadGapTolerant_StartEvicted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	r, gaps := p.ReadGapTolerant(ctx, 1)
+	if gap := <-gaps; gap.MissedFrom != 1 || gap.MissedTo != 7 {
+		t.Fatalf("expected a gap from 1 to 7, found %+v", gap)
+	}
+	for i := 7; i < 10; i++ {
+		if v := <-r; v != i {
+			t.Fatalf("expected %d, found %d", i, v)
+		}
+	}
+}
+
+func TestReadRecent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	collect := func(ch <-chan int) []int {
+		var got []int
+		for v := range ch {
+			got = append(got, v)
+		}
+		return got
+	}
+	empty := MustNewPool[int](ctx, Policy{Count: 10})
+	if got := collect(empty.ReadRecent(ctx, 3)); len(got) != 0 {
+		t.Fatalf("expected nothing from an empty pool, found %v", got)
+	}
+
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2, 3, 4)
+	if got := collect(p.ReadRecent(ctx, 3)); len(got) != 3 || got[0] != 4 || got[2] != 2 {
+		t.Fatalf("expected [4 3 2], found %v", got)
+	}
+	if got := collect(p.ReadRecent(ctx, 0)); len(got) != 0 {
+		t.Fatalf("expected nothing for n of zero, found %v", got)
+	}
+	r := p.ReadRecent(ctx, 10)
+	if err := p.Append(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(r); len(got) != 5 || got[0] != 4 || got[4] != 0 {
+		t.Fatalf("expected [4 3 2 1 0], found %v", got)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var evicted int
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithData(0, 1, 2),
+		WithOnEvict(func(int) { evicted++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := p.Read(ctx, 3)
+	p.Flush()
+	if n := p.Len(); n != 0 {
+		t.Fatalf("expected an empty pool, found %d", n)
+	}
+	if first := p.FirstOffset(); first != 3 {
+		t.Fatalf("expected first offset 3, found %d", first)
+	}
+	if evicted != 3 {
+		t.Fatalf("expected 3 evicted, found %d", evicted)
+	}
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-r; v != 3 {
+		t.Fatalf("expected the waiting reader to receive 3, found %d", v)
+	}
+	p.Close()
+	p.WaitForClose()
+	if evicted != 4 {
+		t.Fatalf("expected 3 flushed and 1 shutdown evictions, found %d", evicted)
+	}
+}
+
+func TestRead_ThousandReaders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	before := runtime.NumGoroutine()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithData(0, 1, 2, 3, 4), WithRequestBuffer[int](4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const readers = 1000
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var n int
+			for range p.ReadN(ctx, 0, 10) {
+				n++
+			}
+			if n != 10 {
+				failed.Add(1)
+			}
+		}()
+	}
+	for i := 5; i < 10; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected all readers to complete")
+	}
+	if n := failed.Load(); n > 0 {
+		t.Fatalf("expected every reader to read 10 elements, %d did not", n)
+	}
+	p.Close()
+	p.WaitForClose()
+	waitForGoroutines(t, before)
+}
+
+// waitForWaitingRead waits for a read to have been told to wait for data not yet fed.
+func waitForWaitingRead[T any](p Pool[T]) {
+	pl := p.(*pool[T])
+	for waiting := false; !waiting; runtime.Gosched() {
+		pl.waitLock.mu.Lock()
+		waiting = pl.waitLock.ch != nil
+		pl.waitLock.mu.Unlock()
+	}
+}
+
+// waitForGoroutines waits for the number of goroutines to fall back to, at most, n.
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for runtime.NumGoroutine() > n {
+		select {
+		case <-deadline:
+			t.Fatalf("expected no more than %d goroutines, found %d", n, runtime.NumGoroutine())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestRead_OneGoroutinePerReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	before := runtime.NumGoroutine()
+
+	const readers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		r := p.ReadN(ctx, ReadLatest, 100)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range r {
+			}
+		}()
+	}
+	// each reader has its own goroutine, along with the goroutine receiving from it
+	limit := before + 2*readers
+	most := 0
+	for i := 0; i < 100; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+		if n := runtime.NumGoroutine(); n > most {
+			most = n
+		}
+	}
+	wg.Wait()
+	if most > limit {
+		t.Fatalf("expected no more than %d goroutines as readers were serviced, found %d", limit, most)
+	}
+	waitForGoroutines(t, before)
+}
+
+func BenchmarkRead_ServiceCycles(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		r := p.ReadN(ctx, ReadLatest, b.N)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range r {
+			}
+		}()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			b.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestPolicy_MaxReaders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10, MaxReaders: 2}, 0)
+	first, cancelFirst := context.WithCancel(ctx)
+	defer cancelFirst()
+	r1 := p.Read(first, 0)
+	r2 := p.Read(ctx, 0)
+	// each reader is registered before it is delivered an element
+	<-r1
+	<-r2
+
+	r3, errc := p.ReadWithErr(ctx, 0)
+	if _, ok := <-r3; ok {
+		t.Fatal("expected the third reader to be rejected")
+	}
+	if err := <-errc; !errors.Is(err, ErrTooManyReaders) {
+		t.Fatalf("expected ErrTooManyReaders, found %v", err)
+	}
+
+	cancelFirst()
+	for range r1 {
+	}
+	// the cancelled reader is unregistered as its channel closes
+	deadline := time.After(2 * time.Second)
+	for {
+		r4, errc := p.ReadWithErr(ctx, 0)
+		v, ok := <-r4
+		if ok && v == 0 {
+			return
+		}
+		if err := <-errc; !errors.Is(err, ErrTooManyReaders) {
+			t.Fatalf("expected a new reader to succeed, found %v", err)
+		}
+		select {
+		case <-deadline:
+			t.Fatal("expected a new reader to succeed once one was cancelled")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestSubscribe_PanicIsRecovered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := &captureLogger{}
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithLogger[int](logger), WithData(0, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan int, 10)
+	stop := p.Subscribe(ctx, 0, func(i int) {
+		if i == 1 {
+			panic("bad element")
+		}
+		received <- i
+	})
+	defer stop()
+	// the subscriber is called with the elements following the one it panicked on
+	for _, want := range []int{0, 2} {
+		if v := <-received; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+	if !logger.contains("Subscribe callback panicked: bad element") {
+		t.Fatalf("expected the panic to be logged, found %q", logger.messages)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	var mu sync.Mutex
+	var got []int
+	received := make(chan struct{}, 10)
+	stop := p.Subscribe(ctx, 0, func(i int) {
+		mu.Lock()
+		got = append(got, i)
+		mu.Unlock()
+		received <- struct{}{}
+	})
+	for i := 0; i < 3; i++ {
+		<-received
+	}
+	// stop returns once the subscriber has ended, so nothing fed after it is passed to fn
+	stop()
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 3 || got[0] != 0 || got[2] != 2 {
+		t.Fatalf("expected [0 1 2], with nothing after cancel, found %v", got)
+	}
+}
+
+func TestSetPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make([]int, 10)
+	for i := range items {
+		items[i] = i
+	}
+	p := MustNewPool[int](ctx, Policy{Count: 10}, items...)
+	r := p.Read(ctx, -1)
+
+	if err := p.SetPolicy(Policy{Count: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Len(); n != 4 {
+		t.Fatalf("expected a tighter policy to trim to 4, found %d", n)
+	}
+	if err := p.SetPolicy(Policy{Count: 20}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 10; i < 20; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := p.Len(); n != 14 {
+		t.Fatalf("expected a looser policy to hold 14, found %d", n)
+	}
+	if got := p.Policy(); got.Count != 20 {
+		t.Fatalf("expected the policy Count 20, found %d", got.Count)
+	}
+	if err := p.SetPolicy(Policy{}); !errors.Is(err, ErrUnconstrainedPolicy) {
+		t.Fatalf("expected an invalid policy to be rejected, found %v", err)
+	}
+
+	// the reader continues in order across the changes
+	prev := -1
+	for v := range r {
+		if v <= prev {
+			t.Fatalf("expected elements in order, found %d after %d", v, prev)
+		}
+		prev = v
+		if v == 19 {
+			break
+		}
+	}
+	if prev != 19 {
+		t.Fatalf("expected the reader to reach 19, ended at %d", prev)
+	}
+}
+
+func TestWaitForData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0)
+	if err := p.WaitForData(ctx, 0); err != nil {
+		t.Fatalf("expected held data to return at once, found %v", err)
+	}
+	waited := make(chan error, 1)
+	go func() {
+		waited <- p.WaitForData(ctx, 1)
+	}()
+	select {
+	case err := <-waited:
+		t.Fatalf("expected the wait to block until fed, found %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if err := p.Append(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the wait to return once fed")
+	}
+
+	wctx, wcancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer wcancel()
+	if err := p.WaitForData(wctx, 5); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context error, found %v", err)
+	}
+}
+
+func TestReadBatch_NeverExceedsMaxBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+	p := MustNewPool[int](ctx, Policy{Count: len(items)}, items...)
+	r := p.ReadBatch(ctx, 0, 7)
+	next := 0
+	for next < len(items) {
+		batch := <-r
+		if len(batch) == 0 || len(batch) > 7 {
+			t.Fatalf("expected batches of 1 to 7 elements, found %d", len(batch))
+		}
+		for _, v := range batch {
+			if v != next {
+				t.Fatalf("expected %d, found %d", next, v)
+			}
+			next++
+		}
+	}
+}
+
+func benchmarkReadBatch(b *testing.B, batch int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make([]int, 1024)
+	p := MustNewPool[int](ctx, Policy{Count: len(items)}, items...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rctx, rcancel := context.WithCancel(ctx)
+		if batch > 0 {
+			r := p.ReadBatch(rctx, 0, batch)
+			for n := 0; n < len(items); {
+				n += len(<-r)
+			}
+		} else {
+			r := p.Read(rctx, 0)
+			for n := 0; n < len(items); n++ {
+				<-r
+			}
+		}
+		rcancel()
+	}
+}
+
+func BenchmarkReadBatch_Single(b *testing.B) {
+	benchmarkReadBatch(b, 0)
+}
+
+func BenchmarkReadBatch_64(b *testing.B) {
+	benchmarkReadBatch(b, 64)
+}
+
+func TestCloseAndDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const n = 50
+	p := MustNewPool[int](ctx, Policy{Count: n})
+	for i := 0; i < n; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := p.Read(ctx, 0)
+	drained := make(chan error, 1)
+	go func() {
+		drained <- p.CloseAndDrain(ctx)
+	}()
+	var got int
+	for v := range r {
+		if v != got {
+			t.Fatalf("expected %d, found %d", got, v)
+		}
+		got++
+	}
+	if got != n {
+		t.Fatalf("expected the reader to receive all %d before closing, found %d", n, got)
+	}
+	if err := <-drained; err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(ctx, n); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected a drained pool to be closed, found %v", err)
+	}
+}
+
+func TestCloseAndDrain_Timeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	_ = p.Read(ctx, 0) // never received from, so the pool never drains
+	dctx, dcancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer dcancel()
+	if err := p.CloseAndDrain(dctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the drain to time out, found %v", err)
+	}
+	if err := p.Ping(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected the pool to be closed once the drain timed out, found %v", err)
+	}
+}
+
+func TestFeedWithIdle_EndsWhenIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	ch := make(chan int)
+	done := p.FeedWithIdle(ctx, ch, 20*time.Millisecond)
+	ch <- 1
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the feed to end once idle")
+	}
+	if n := p.Len(); n != 1 {
+		t.Fatalf("expected 1 element fed, found %d", n)
+	}
+	select {
+	case ch <- 2:
+		t.Fatal("expected nothing to receive from the abandoned channel")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestPolicy_SizeOnEmptyPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[[]byte](ctx, Policy{Size: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Len(); n != 0 {
+		t.Fatalf("expected an empty pool, found %d", n)
+	}
+	if err := p.Append(ctx, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(ctx, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if stats := p.Stats(); stats.Length != 2 || stats.Evicted != 0 {
+		t.Fatalf("expected the zero size and tiny elements to be held, found %+v", stats)
+	}
+	d := newOffsetData[[]byte](0, nil, nil)
+	d.TrimToSize(0)
+	if d.Size() != 0 || d.Length() != 0 {
+		t.Fatalf("expected empty data to stay empty, found %d elements of %d bytes", d.Length(), d.Size())
+	}
+}
+
+func TestRead_EndRelativeOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, length := range []int{1, 5, 10, 11, 25} {
+		items := make([]int, length)
+		for i := range items {
+			items[i] = i
+		}
+		p := MustNewPool[int](ctx, Policy{Count: 100}, items...)
+		// -11 is 10 back from the end, clamped to the first available
+		want := length - 10
+		if want < 0 {
+			want = 0
+		}
+		if v := <-p.Read(ctx, -11); v != want {
+			t.Fatalf("length %d: expected to start at %d, found %d", length, want, v)
+		}
+		p.Close()
+	}
+}
+
+func TestTryRead(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 10, 11, 12, 13)
+	if v, ok := p.TryRead(2); !ok || v != 12 {
+		t.Fatalf("expected 12 at offset 2, found %d, %v", v, ok)
+	}
+	if v, ok := p.TryRead(4); ok {
+		t.Fatalf("expected an offset not yet fed to be missing, found %d", v)
+	}
+	if v, ok := p.TryRead(0); ok {
+		t.Fatalf("expected an evicted offset to be missing, found %d", v)
+	}
+}
+
+func TestWaitForCloseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 5})
+
+	wctx, wcancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer wcancel()
+	if err := p.WaitForCloseContext(wctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, found %v", err)
+	}
+	if err := p.Append(ctx, 1); err != nil {
+		t.Fatalf("expected the pool to remain open after a timed out wait, found %v", err)
+	}
+
+	go p.Close()
+	wctx2, wcancel2 := context.WithTimeout(ctx, 2*time.Second)
+	defer wcancel2()
+	if err := p.WaitForCloseContext(wctx2); err != nil {
+		t.Fatalf("expected the pool to close before the timeout, found %v", err)
+	}
+	p.WaitForClose()
+}
+
+func TestFeedOnce_RejectsDuplicate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	ch := make(chan int)
+	done, err := p.FeedOnce(ctx, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.FeedOnce(ctx, ch); !errors.Is(err, ErrAlreadyFed) {
+		t.Fatalf("expected ErrAlreadyFed, found %v", err)
+	}
+	ch <- 1
+	ch <- 2
+	close(ch)
+	<-done
+	if s := p.Snapshot(); len(s) != 2 || s[0] != 1 || s[1] != 2 {
+		t.Fatalf("expected the channel fed once, found %v", s)
+	}
+
+	// once the first feed ends, the channel may be fed again
+	ch2 := make(chan int)
+	done, err = p.FeedOnce(ctx, ch2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(ch2)
+	<-done
+	if done, err = p.FeedOnce(ctx, ch2); err != nil {
+		t.Fatalf("expected a finished channel to be fed again, found %v", err)
+	}
+	<-done
+}
+
+func TestReadFrom_Trimmed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2)
+	earliest := p.ReadFrom(ctx, FromEarliest)
+	follow := p.ReadFrom(ctx, FromEarliestFollow)
+	latest := p.ReadFrom(ctx, FromLatest)
+	if v := <-earliest; v != 0 {
+		t.Fatalf("expected FromEarliest to start at 0, found %d", v)
+	}
+	if v := <-follow; v != 0 {
+		t.Fatalf("expected FromEarliestFollow to start at 0, found %d", v)
+	}
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-latest; v != 3 {
+		t.Fatalf("expected FromLatest to start at 3, found %d", v)
+	}
+
+	// trim away the offsets the readers are waiting to read
+	for i := 4; i < 10; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for v := range earliest {
+		if v > 2 {
+			t.Fatalf("expected FromEarliest to end once its offset was evicted, found %d", v)
+		}
+	}
+	var last int
+	for v := range follow {
+		if v > 2 && v < 7 {
+			t.Fatalf("expected FromEarliestFollow to jump the evicted offsets, found %d", v)
+		}
+		if last = v; v == 9 {
+			break
+		}
+	}
+	if last != 9 {
+		t.Fatalf("expected FromEarliestFollow to read on to 9, found %d", last)
+	}
+}
+
+// TestConcurrentCommands is intended to be run with -race, so any access to the pool data off the pool thread is reported.
+func TestConcurrentCommands(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 50})
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				fn(i)
+			}
+		}()
+	}
+	for f := 0; f < 2; f++ {
+		ch := make(chan int)
+		p.Feed(ctx, ch)
+		run(func(i int) { ch <- i })
+	}
+	run(func(i int) {
+		if err := p.SetPolicy(Policy{Count: 10 + i%50}); err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(int) {
+		if s := p.Stats(); s.Length > 60 {
+			t.Errorf("expected no more than 60 elements, found %d", s.Length)
+		}
+	})
+	run(func(int) { _ = p.Snapshot() })
+	rctx, rcancel := context.WithCancel(ctx)
+	defer rcancel()
+	for r := 0; r < 3; r++ {
+		go func(r <-chan int) {
+			for range r {
+			}
+		}(p.ReadFrom(rctx, FromEarliestFollow))
+	}
+	wg.Wait()
+}
+
+func TestReadAll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2, 3, 4)
+	if all, err := p.ReadAll(ctx, 0, 3); err != nil || len(all) != 3 || all[2] != 2 {
+		t.Fatalf("expected [0 1 2], found %v, %v", all, err)
+	}
+
+	// with no max, the read ends once the pool has closed
+	go func() {
+		waitForWaitingRead[int](p)
+		p.Close()
+	}()
+	if all, err := p.ReadAll(ctx, 0, 0); err != nil || len(all) != 5 {
+		t.Fatalf("expected all 5 elements of the closed pool, found %v, %v", all, err)
+	}
+
+	p = MustNewPool[int](ctx, Policy{Count: 10}, 0, 1)
+	rctx, rcancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer rcancel()
+	all, err := p.ReadAll(rctx, 0, 10)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, found %v", err)
+	}
+	if len(all) != 2 || all[0] != 0 || all[1] != 1 {
+		t.Fatalf("expected the elements read before the deadline, found %v", all)
+	}
+}
+
+type sizedRecord struct {
+	ID   int
+	Data []byte
+}
+
+func (r sizedRecord) PoolSize() uint64 {
+	return uint64(len(r.Data))
+}
+
+func TestPolicy_SizeWithSizerType(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[sizedRecord](ctx, Policy{Size: 100})
+	for i, n := range []int{40, 30, 20, 60} {
+		if err := p.Append(ctx, sizedRecord{ID: i, Data: make([]byte, n)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// adding 60 takes the size over 100, until the oldest two are evicted
+	if s := p.Snapshot(); len(s) != 2 || s[0].ID != 2 || s[1].ID != 3 {
+		t.Fatalf("expected records 2 and 3 to remain, found %v", s)
+	}
+}
+
+func TestWaitForOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 2})
+	var fed atomic.Int64
+	fed.Store(-1)
+	unblocked := make(chan int64)
+	go func() {
+		if err := p.WaitForOffset(ctx, 5); err != nil {
+			t.Error(err)
+		}
+		unblocked <- fed.Load()
+	}()
+	for i := 0; i < 10; i++ {
+		fed.Store(int64(i))
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+		if i == 5 {
+			if n := <-unblocked; n != 5 {
+				t.Fatalf("expected the wait to end when offset 5 was fed, ended at %d", n)
+			}
+		}
+	}
+
+	// an offset already evicted has been produced, so does not wait
+	if err := p.WaitForOffset(ctx, 0); err != nil {
+		t.Fatalf("expected an evicted offset not to wait, found %v", err)
+	}
+}
+
+// orderedRequest records the order the pool thread responds to requests
+type orderedRequest struct {
+	*requestImpl[int]
+	name  string
+	order *[]string
+}
+
+func (rq *orderedRequest) Respond(resp response[int]) {
+	*rq.order = append(*rq.order, rq.name)
+	rq.requestImpl.Respond(resp)
+}
+
+func TestReadPriority_ServedFirst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 1, 2, 3).(*pool[int])
+	const lows = 8
+	var order []string
+	var rqs []*orderedRequest
+	for i := 0; i < lows; i++ {
+		rqs = append(rqs, &orderedRequest{requestImpl: newRequest[int](ctx, make(chan int, 3), 0, 0, nil), name: "low", order: &order})
+	}
+	prio := &orderedRequest{requestImpl: newRequest[int](ctx, make(chan int, 3), 0, 0, nil), name: "priority", order: &order}
+	prio.priority = 1
+	rqs = append(rqs, prio)
+
+	// the pool thread is held until every request is queued, the priority one last
+	p.query(func(data *offsetData[int]) {
+		for _, rq := range rqs {
+			p.requestQueue(rq) <- rq
+		}
+	})
+	for _, rq := range rqs {
+		select {
+		case <-rq.Response():
+		case <-time.After(2 * time.Second):
+			t.Fatal("request was not serviced")
+		}
+	}
+	if order[0] != "priority" {
+		t.Fatalf("expected the priority read to be served ahead of the others, found %v", order)
+	}
+}
+
+func TestRead_ClosedOnSharedCancel(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := MustNewPool[int](ctx, Policy{Count: 5}, 0, 1, 2)
+		var readers []<-chan int
+		for j := 0; j < 10; j++ {
+			// readers both waiting for data and holding data undelivered
+			readers = append(readers, p.Read(ctx, ReadLatest), p.Read(ctx, 0))
+		}
+		cancel()
+		deadline := time.After(2 * time.Second)
+		for _, r := range readers {
+		drain:
+			for {
+				select {
+				case _, ok := <-r:
+					if !ok {
+						break drain
+					}
+				case <-deadline:
+					t.Fatal("expected every reader to close once the shared context was cancelled")
+				}
+			}
+		}
+	}
+}
+
+func TestNewPool_CopiesInitialData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	data := []int{0, 1, 2}
+	p := MustNewPool[int](ctx, Policy{Count: 5}, data...)
+	withData, err := NewPoolWithOptions[int](ctx, Policy{Count: 5}, WithData(data...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0], data[2] = 10, 12
+	for _, s := range [][]int{p.Snapshot(), withData.Snapshot()} {
+		if len(s) != 3 || s[0] != 0 || s[2] != 2 {
+			t.Fatalf("expected the pool unchanged by the caller's slice, found %v", s)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2, 3, 4)
+	for offset, want := range map[int64]bool{0: false, 1: false, 2: true, 3: true, 4: true, 5: false, 6: false} {
+		if got := p.Contains(offset); got != want {
+			t.Fatalf("expected Contains(%d) to be %v, found %v", offset, want, got)
+		}
+	}
+	if p.Contains(-1) {
+		t.Fatal("expected a negative offset not to be contained")
+	}
+}
+
+// TestFeed_WhileCancelled is intended to be run with -race, feeding from every feed method as the pool shuts down.
+func TestFeed_WhileCancelled(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := MustNewPool[int](ctx, Policy{Count: 10}, 0)
+		ch := make(chan int)
+		batches := make(chan []int)
+		// Feed returns the done channel of the pool
+		done := p.Feed(ctx, ch)
+		p.FeedBatches(ctx, batches)
+		var wg sync.WaitGroup
+		feeders := []func(j int){
+			func(j int) { _ = p.Append(ctx, j) },
+			func(j int) { _ = p.FeedSlice(ctx, []int{j, j}) },
+			func(j int) {
+				select {
+				case ch <- j:
+				case <-done:
+				}
+			},
+			func(j int) {
+				select {
+				case batches <- []int{j}:
+				case <-done:
+				}
+			},
+		}
+		for _, feed := range feeders {
+			wg.Add(1)
+			go func(feed func(int)) {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					feed(j)
+				}
+			}(feed)
+		}
+		// the pool is cancelled after a varying number of feeds
+		for p.Stats().Fed < i {
+			runtime.Gosched()
+		}
+		cancel()
+		wg.Wait()
+		p.WaitForClose()
+	}
+}
+
+func TestFeedSlice_BatchesHeldTogether(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 1000})
+	batches := make(chan []int)
+	fed := make(chan struct{})
+	go func() {
+		defer close(fed)
+		for i := 0; i < 10; i++ {
+			if err := p.FeedSlice(ctx, []int{100 + i*10, 101 + i*10, 102 + i*10}); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	p.FeedBatches(ctx, batches)
+	for i := 0; i < 10; i++ {
+		batches <- []int{200 + i*10, 201 + i*10, 202 + i*10}
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-fed
+	if err := p.WaitForOffset(ctx, 69); err != nil {
+		t.Fatal(err)
+	}
+	s := p.Snapshot()
+	if len(s) != 70 {
+		t.Fatalf("expected 70 elements, found %d", len(s))
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 100 {
+			continue
+		}
+		// each batch of three is held in order, with no other element between
+		if i+2 >= len(s) || s[i]%10 != 0 || s[i+1] != s[i]+1 || s[i+2] != s[i]+2 {
+			t.Fatalf("expected a contiguous batch from %d, found %v", i, s)
+		}
+		i += 2
+	}
+}
+
+func TestFeedSlice_OverflowBlockHoldsCount(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3, Overflow: OverflowBlock})
+	if err := p.FeedSlice(ctx, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}); err != nil {
+		t.Fatal(err)
+	}
+	if l := p.Len(); l != 3 {
+		t.Fatalf("expected the pool to hold its Count of 3, found %d", l)
+	}
+	// no other feed is accepted until the rest of the slice is held, the slice itself remaining in flight
+	appended := make(chan error, 1)
+	go func() {
+		appended <- p.Append(ctx, 10)
+	}()
+	waitForWaitingFeeds(t, ctx, p, 2)
+	if s := p.Snapshot(); len(s) != 3 || s[2] != 2 {
+		t.Fatalf("expected the append to wait on the slice, found %v", s)
+	}
+
+	// the rest of the slice is added as the reader makes room
+	r := p.Read(ctx, 0)
+	for want := 0; want <= 10; want++ {
+		if v := <-r; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+		if l := p.Len(); l > 3 {
+			t.Fatalf("expected the pool to hold no more than its Count of 3, found %d", l)
+		}
+	}
+	if err := <-appended; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func benchmarkFeed(b *testing.B, batch int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 1000})
+	items := make([]int, batch)
+	b.ResetTimer()
+	for i := 0; i < b.N; i += batch {
+		if batch == 1 {
+			if err := p.Append(ctx, i); err != nil {
+				b.Fatal(err)
+			}
+			continue
+		}
+		if err := p.FeedSlice(ctx, items); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFeed_PerItem(b *testing.B) {
+	benchmarkFeed(b, 1)
+}
+
+func BenchmarkFeed_Slice100(b *testing.B) {
+	benchmarkFeed(b, 100)
+}
+
+func TestQuiesce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 50}, WithFeedBuffer[int](100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		go func(r <-chan int) {
+			for range r {
+			}
+		}(p.Read(ctx, ReadLatest))
+	}
+	for i := 0; i < 100; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Quiesce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Snapshot(); len(s) != 50 || s[0] != 50 || s[49] != 99 {
+		t.Fatalf("expected the last 50 elements held once quiet, found %v", s)
+	}
+	for _, lag := range p.ReaderLags() {
+		if lag != 0 {
+			t.Fatalf("expected every reader to have caught up once quiet, found lags %v", p.ReaderLags())
+		}
+	}
+}
+
+func TestQuiesce_WaitsForBlockedFeeds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 2, Overflow: OverflowBlock}, 0, 1)
+	// the pool is full, so an Append and a Feed are both left waiting to send
+	go func() {
+		_ = p.Append(ctx, 2)
+	}()
+	ch := make(chan int)
+	p.Feed(ctx, ch)
+	ch <- 3
+	for p.(*pool[int]).feeding.Load() != 2 {
+		runtime.Gosched()
+	}
+	cancelled, cancelQuiesce := context.WithCancel(ctx)
+	cancelQuiesce()
+	if err := p.Quiesce(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the waiting feeds to keep the pool busy, found %v", err)
+	}
+
+	r := p.Read(ctx, 0)
+	seen := map[int]bool{}
+	for i := 0; i < 4; i++ {
+		seen[<-r] = true
+	}
+	if err := p.Quiesce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 4 || p.Stats().Fed != 4 {
+		t.Fatalf("expected all 4 elements fed and read once quiet, read %v, found %+v", seen, p.Stats())
+	}
+}
+
+func TestReadNWithErr_EndedByClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1)
+	r, errc := p.ReadNWithErr(ctx, 0, 5)
+	<-r
+	<-r
+	p.Close()
+	for range r {
+	}
+	if err := <-errc; !errors.Is(err, ErrPoolClosed) || errors.Is(err, ErrComplete) {
+		t.Fatalf("expected a read ended by the pool closing to report ErrPoolClosed, found %v", err)
+	}
+}
+
+func TestReadWithTimeout_AbandonedReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2)
+	before := runtime.NumGoroutine()
+	// the reader is abandoned after the first element, with its context never cancelled
+	r := p.ReadWithTimeout(ctx, 0, 20*time.Millisecond)
+	if v := <-r; v != 0 {
+		t.Fatalf("expected 0, found %d", v)
+	}
+	deadline := time.After(2 * time.Second)
+	for p.Stats().Readers > 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected the stalled reader to be removed")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	waitForGoroutines(t, before)
+	// the stalled element is not delivered, the channel being closed once the reader has given up
+	if v, ok := <-r; ok {
+		t.Fatalf("expected the reader to have closed, found %d", v)
+	}
+}
+
+func TestIndexFor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2, 3, 4)
+	for _, offset := range []int64{0, 1, 5, 100} {
+		if i, ok := p.IndexFor(offset); ok {
+			t.Fatalf("expected offset %d to have no index, found %d", offset, i)
+		}
+	}
+	for offset, want := range map[int64]int{2: 0, 3: 1, 4: 2} {
+		if i, ok := p.IndexFor(offset); !ok || i != want {
+			t.Fatalf("expected offset %d at index %d, found %d, %v", offset, want, i, ok)
+		}
+	}
+}
+
+func TestPolicy_IdleTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const timeout = 10 * time.Millisecond
+	p := MustNewPool[int](ctx, Policy{Count: 3, IdleTimeout: timeout})
+	// an attached reader keeps the pool active, even while it waits for data
+	rctx, rcancel := context.WithCancel(ctx)
+	r := p.Read(rctx, ReadLatest)
+	wctx, wcancel := context.WithTimeout(ctx, 10*timeout)
+	defer wcancel()
+	if err := p.WaitForCloseContext(wctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the pool to stay open with a reader attached, found %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatalf("expected the pool to stay open with a reader attached, found %v", err)
+		}
+	}
+
+	// once the reader detaches, the pool closes, though no sooner than the timeout after its last activity
+	start := time.Now()
+	rcancel()
+	for range r {
+	}
+	cctx, ccancel := context.WithTimeout(ctx, 2*time.Second)
+	defer ccancel()
+	if err := p.WaitForCloseContext(cctx); err != nil {
+		t.Fatalf("expected the idle pool to close, found %v", err)
+	}
+	if d := time.Since(start); d < timeout {
+		t.Fatalf("expected the pool to close no sooner than %v after the reader detached, closed after %v", timeout, d)
+	}
+}
+
+func TestReadSince(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := newFakeClock()
+	start := clock.Now()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, withClock[int](clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+		// the element is timed once added, which the ping waits for
+		if err := p.Ping(ctx); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Minute)
+	}
+	for since, want := range map[time.Duration]int{-time.Hour: 0, 0: 0, 30 * time.Second: 1, time.Minute: 1, 2 * time.Minute: 2} {
+		if v := <-p.ReadSince(ctx, start.Add(since)); v != want {
+			t.Fatalf("expected a read since %v to start at %d, found %d", since, want, v)
+		}
+	}
+	// a time after the latest element reads only new elements
+	r := p.ReadSince(ctx, start.Add(time.Hour))
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-r; v != 3 {
+		t.Fatalf("expected a read since a later time to start at 3, found %d", v)
+	}
+}
+
+func TestFeedRecoverable_UnsentOnClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// a full, blocking pool holds the feed with the element it has pulled from the channel
+	p := MustNewPool[int](ctx, Policy{Count: 2, Overflow: OverflowBlock}, 0, 1)
+	ch := make(chan int, 1)
+	done, unsent := p.FeedRecoverable(ctx, ch)
+	ch <- 2
+	// once the buffered channel accepts another, the feed has pulled 2
+	ch <- 3
+	p.Close()
+	<-done
+	if v, ok := unsent(); !ok || v != 2 {
+		t.Fatalf("expected 2 to be recovered, found %d, %v", v, ok)
+	}
+	if v := <-ch; v != 3 {
+		t.Fatalf("expected 3 left in the channel, found %d", v)
+	}
+
+	p = MustNewPool[int](ctx, Policy{Count: 2})
+	done, unsent = p.FeedRecoverable(ctx, ch)
+	p.Close()
+	<-done
+	if v, ok := unsent(); ok {
+		t.Fatalf("expected no element to be recovered, found %d", v)
+	}
+}
+
+func TestReadWindow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 5}, 0, 1, 2, 3, 4, 5, 6, 7)
+	tests := []struct {
+		from, to int64
+		want     []int
+	}{
+		{4, 7, []int{4, 5, 6}}, // in range
+		{1, 5, []int{3, 4}},    // partially evicted
+		{6, 20, []int{6, 7}},   // partially in the future
+		{8, 12, nil},           // entirely in the future
+		{5, 5, nil},            // empty
+		{0, 20, []int{3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		window, err := p.ReadWindow(tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("[%d, %d): unexpected error %v", tt.from, tt.to, err)
+		}
+		if len(window) != len(tt.want) {
+			t.Fatalf("[%d, %d): expected %v, found %v", tt.from, tt.to, tt.want, window)
+		}
+		for i, v := range tt.want {
+			if window[i] != v {
+				t.Fatalf("[%d, %d): expected %v, found %v", tt.from, tt.to, tt.want, window)
+			}
+		}
+	}
+	if _, err := p.ReadWindow(0, 3); !errors.Is(err, ErrOffsetEvicted) {
+		t.Fatalf("expected an evicted window to be reported, found %v", err)
+	}
+}
+
+func TestTrimEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0, 1, 2)
+	events := p.TrimEvents()
+	for i := 3; i < 5; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.FeedSlice(ctx, []int{5, 6, 7, 8, 9}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []TrimEvent{{1, 0, 1}, {1, 1, 2}, {5, 2, 7}} {
+		select {
+		case e := <-events:
+			if e != want {
+				t.Fatalf("expected %+v, found %+v", want, e)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %+v to be reported", want)
+		}
+	}
+
+	// with no one listening, events are dropped rather than stalling the pool
+	for i := 0; i < 2*trimEventBuffer; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(events); n != trimEventBuffer {
+		t.Fatalf("expected %d events held, found %d", trimEventBuffer, n)
+	}
+	p.Close()
+	for range events {
+	}
+}
+
+func TestTrimEvents_FlushAndReset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var evicted int
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithData(0, 1, 2),
+		WithOnEvict(func(i int) {
+			evicted++
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := p.TrimEvents()
+	p.Flush()
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Reset(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	// the elements removed are reported as they are to OnEvict
+	for _, want := range []TrimEvent{{3, 0, 3}, {1, 3, 4}} {
+		select {
+		case e := <-events:
+			if e != want {
+				t.Fatalf("expected %+v, found %+v", want, e)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %+v to be reported", want)
+		}
+	}
+	if err := p.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if evicted != 4 {
+		t.Fatalf("expected 4 elements passed to OnEvict, found %d", evicted)
+	}
+}
+
+func TestNewPoolCancelable(t *testing.T) {
+	p, cancel, err := NewPoolCancelable[int](Policy{Count: 3}, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	r := p.Read(context.Background(), ReadLatest)
+	cancel()
+	wctx, wcancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer wcancel()
+	if err := p.WaitForCloseContext(wctx); err != nil {
+		t.Fatalf("expected the pool to close once cancelled, found %v", err)
+	}
+	if _, ok := <-r; ok {
+		t.Fatal("expected the read to end once the pool was cancelled")
+	}
+	if err := p.Append(context.Background(), 3); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, found %v", err)
+	}
+
+	if _, cancel, err := NewPoolCancelable[int](Policy{Count: -1}); err == nil {
+		cancel()
+		t.Fatal("expected an invalid policy to be rejected")
+	}
+}
+
+func TestPing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	release := make(chan struct{})
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 1}, WithData(0),
+		WithOnEvict(func(int) {
+			<-release
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pctx, pcancel := context.WithTimeout(ctx, time.Second)
+	defer pcancel()
+	if err := p.Ping(pctx); err != nil {
+		t.Fatalf("expected a responsive pool, found %v", err)
+	}
+
+	// the eviction callback wedges the pool thread
+	if err := p.Append(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	wctx, wcancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer wcancel()
+	if err := p.Ping(wctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a wedged pool to time out, found %v", err)
+	}
+	close(release)
+	if err := p.Ping(pctx); err != nil {
+		t.Fatalf("expected the released pool to respond, found %v", err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var evicted []int
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithData(0, 1, 2, 3, 4),
+		WithOnEvict(func(i int) {
+			mu.Lock()
+			defer mu.Unlock()
+			evicted = append(evicted, i)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, errc := p.ReadWithErr(ctx, ReadLatest)
+	tolerant, gaps := p.ReadGapTolerant(ctx, ReadLatest)
+
+	if err := p.Reset(ctx, 100, 101); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Snapshot(); len(s) != 2 || s[0] != 100 || s[1] != 101 {
+		t.Fatalf("expected only the new data, found %v", s)
+	}
+	if first, next := p.FirstOffset(), p.NextOffset(); first != 0 || next != 2 {
+		t.Fatalf("expected offsets 0 to 2, found %d to %d", first, next)
+	}
+	mu.Lock()
+	if len(evicted) != 5 || evicted[0] != 0 || evicted[4] != 4 {
+		t.Fatalf("expected the old elements passed to OnEvict, found %v", evicted)
+	}
+	mu.Unlock()
+
+	// the gap tolerant reader continues with the new data, the other ends
+	select {
+	case gap := <-gaps:
+		if gap.MissedFrom != 5 || gap.MissedTo != 0 || !gap.Reset {
+			t.Fatalf("expected a reset gap from 5 to 0, found %+v", gap)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the gap tolerant reader to be notified of the reset")
+	}
+	for _, want := range []int{100, 101} {
+		if v := <-tolerant; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+	for range plain {
+	}
+	if err := <-errc; !errors.Is(err, ErrOffsetEvicted) {
+		t.Fatalf("expected ErrOffsetEvicted, found %v", err)
+	}
+}
+
+func TestReset_UnstartedReaderReadsNewData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10})
+	// the first available offset is resolved against the reset data, whether the read starts before or after the reset
+	r, errc := p.ReadWithErr(ctx, -1)
+	if err := p.Reset(ctx, 100, 101, 102); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []int{100, 101, 102} {
+		v, ok := <-r
+		if !ok {
+			t.Fatalf("expected %d, read ended with %v", want, <-errc)
+		}
+		if v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+}
+
+func TestReset_ResumeFromRecordedOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2, 3, 4)
+	recorded := p.Stats()
+	// a gap tolerant reader waits at the recorded offset across the reset
+	tolerant, gaps := p.ReadGapTolerant(ctx, recorded.NextOffset)
+	if err := p.Reset(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(ctx, 101); err != nil {
+		t.Fatal(err)
+	}
+	if next := p.NextOffset(); next != 2 {
+		t.Fatalf("expected offsets to restart at zero, found next offset %d", next)
+	}
+
+	select {
+	case gap := <-gaps:
+		if !gap.Reset || gap.MissedFrom != recorded.NextOffset || gap.MissedTo != 0 {
+			t.Fatalf("expected a reset gap from %d to 0, found %+v", recorded.NextOffset, gap)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the waiting reader to be notified of the reset")
+	}
+	for _, want := range []int{100, 101} {
+		if v := <-tolerant; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+
+	// a reader resuming from the recorded offset detects the reset from the count of resets
+	current := p.Stats()
+	if current.Resets != recorded.Resets+1 {
+		t.Fatalf("expected %d resets, found %d", recorded.Resets+1, current.Resets)
+	}
+	if err := p.WaitForOffset(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-p.Read(ctx, current.FirstOffset); v != 100 {
+		t.Fatalf("expected the resumed read to start with the new data, found %d", v)
+	}
+}
+
+func TestReset_ReaderMidDelivery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2, 3, 4)
+	plain, errc := p.ReadWithErr(ctx, 0)
+	tolerant, gaps := p.ReadGapTolerant(ctx, 0)
+	// each reader is handed all five elements in one delivery, of which it has received only the first
+	if v := <-plain; v != 0 {
+		t.Fatalf("expected 0, found %d", v)
+	}
+	if v := <-tolerant; v != 0 {
+		t.Fatalf("expected 0, found %d", v)
+	}
+	if err := p.Reset(ctx, 100, 101, 102); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rest of the delivery may still be received, before the readers learn of the reset
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case v, ok := <-plain:
+			if ok && v >= 100 {
+				t.Fatalf("expected the plain reader to end with the reset, found %d", v)
+			}
+			if ok {
+				continue
+			}
+			if err := <-errc; !errors.Is(err, ErrOffsetEvicted) {
+				t.Fatalf("expected ErrOffsetEvicted, found %v", err)
+			}
+		case <-timeout:
+			t.Fatal("expected the plain reader to end with the reset")
+		}
+		break
+	}
+	var gap GapEvent
+	for gap == (GapEvent{}) {
+		select {
+		case v := <-tolerant:
+			if v >= 100 {
+				t.Fatalf("expected the gap ahead of the new data, found %d", v)
+			}
+		case gap = <-gaps:
+		case <-timeout:
+			t.Fatal("expected the gap tolerant reader to be notified of the reset")
+		}
+	}
+	if gap.MissedFrom != 5 || gap.MissedTo != 0 || !gap.Reset {
+		t.Fatalf("expected a reset gap from 5 to 0, found %+v", gap)
+	}
+	for _, want := range []int{100, 101, 102} {
+		if v := <-tolerant; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+}
+
+func TestReadIndexed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// each element is its own offset, the first two already trimmed
+	p := MustNewPool[int](ctx, Policy{Count: 5}, 0, 1, 2, 3, 4, 5, 6)
+	r := p.ReadIndexed(ctx, -1)
+	if e := <-r; e.Offset != 2 || e.Value != 2 {
+		t.Fatalf("expected 2 at offset 2, found %d at %d", e.Value, e.Offset)
+	}
+	for i := 7; i < 10; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for want := int64(3); want < 10; want++ {
+		if e := <-r; e.Offset != want || int64(e.Value) != e.Offset {
+			t.Fatalf("expected %d at offset %d, found %d at %d", want, want, e.Value, e.Offset)
+		}
+	}
+
+	// the offsets of elements removed from within a keyed pool are passed over
+	keyed, err := NewKeyedPool[int, int](ctx, Policy{Count: 10}, func(i int) int { return i % 10 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 1, 2, 11} {
+		if err := keyed.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kr := keyed.ReadIndexed(ctx, 0)
+	for _, w := range []Indexed[int]{{0, 0}, {2, 2}, {3, 11}} {
+		if e := <-kr; e != w {
+			t.Fatalf("expected %+v, found %+v", w, e)
+		}
+	}
+}
+
+func TestPolicy_EvictConsumedByAllReaders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 100, EvictConsumed: true}, WithData(0, 1, 2, 3, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow := p.Read(ctx, 0)
+	fast := p.Read(ctx, 0)
+	for i := 0; i < 5; i++ {
+		<-fast
+	}
+	// wait for the fast reader to have requested the offset following the last element
+	pl := p.(*pool[int])
+	for requested := false; !requested; runtime.Gosched() {
+		pl.query(func(data *offsetData[int]) {
+			for _, offset := range pl.readers {
+				requested = requested || offset == data.NextOffset()
+			}
+		})
+	}
+	if n := p.Len(); n != 5 {
+		t.Fatalf("expected the elements to be kept until both readers consume them, found %d", n)
+	}
+	for i := 0; i < 5; i++ {
+		<-slow
+	}
+	deadline := time.After(2 * time.Second)
+	for p.Len() > 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the elements consumed by both readers to be evicted, found %d", p.Len())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestPolicy_EvictConsumedKeepsElementsBeforeReaderStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 100, EvictConsumed: true}, WithData(0, 1, 2, 3, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rctx, rcancel := context.WithCancel(ctx)
+	r := p.Read(rctx, 3)
+	for _, want := range []int{3, 4} {
+		if v := <-r; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+	for lags := p.ReaderLags(); lags[0] != 0; lags = p.ReaderLags() {
+		time.Sleep(time.Millisecond)
+	}
+	// the reader never read the elements before where it started, so they are kept, along with those after them
+	if s := p.Snapshot(); len(s) != 5 || s[0] != 0 {
+		t.Fatalf("expected the elements the reader did not read to be kept, found %v", s)
+	}
+
+	// a reader from the start reads every element, so they are evicted once the mid-pool reader ends
+	first := p.Read(ctx, 0)
+	for want := 0; want < 5; want++ {
+		if v := <-first; v != want {
+			t.Fatalf("expected %d, found %d", want, v)
+		}
+	}
+	rcancel()
+	for range r {
+	}
+	deadline := time.After(2 * time.Second)
+	for p.Len() > 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the elements consumed by the remaining reader to be evicted, found %v", p.Snapshot())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestFeed_LatencyUnderReadLoad(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 1000})
+	for i := 0; i < 50; i++ {
+		go func(r <-chan int) {
+			for range r {
+			}
+		}(p.ReadFrom(ctx, FromEarliestFollow))
+	}
+	// a busy feeder keeps the readers resubmitting
+	go func() {
+		for i := 0; ctx.Err() == nil; i++ {
+			_ = p.Append(ctx, -i)
+		}
+	}()
+	for p.Stats().Fed == 0 {
+		runtime.Gosched()
+	}
+
+	const feeds = 100
+	var total, most time.Duration
+	for i := 0; i < feeds; i++ {
+		start := time.Now()
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+		// the element is stored once a following ping is answered, both being serviced by the pool thread
+		if err := p.Ping(ctx); err != nil {
+			t.Fatal(err)
+		}
+		d := time.Since(start)
+		total += d
+		if d > most {
+			most = d
+		}
+	}
+	t.Logf("mean feed latency %v, most %v", total/feeds, most)
+	if mean := total / feeds; mean > 20*time.Millisecond {
+		t.Fatalf("expected a mean feed latency under 20ms, found %v", mean)
+	}
+}
+
+// payload is a named byte slice type, sized by its length in the same way as []byte.
+type payload []byte
+
+func TestPolicy_SizeOfNamedByteSlices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[payload](ctx, Policy{Size: 100})
+	for _, n := range []int{40, 30, 20, 60} {
+		if err := p.Append(ctx, make(payload, n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := p.Snapshot(); len(s) != 2 || len(s[0]) != 20 || len(s[1]) != 60 {
+		t.Fatalf("expected the payloads of 20 and 60 bytes held, found %d payloads", len(s))
+	}
+}
+
+func TestPolicy_SizeOfByteSlices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const limit = 1000
+	p := MustNewPool[[]byte](ctx, Policy{Size: limit})
+	var lengths []int
+	for i := 0; i < 200; i++ {
+		n := (i*37)%300 + 1
+		lengths = append(lengths, n)
+		if err := p.Append(ctx, make([]byte, n)); err != nil {
+			t.Fatal(err)
+		}
+		// the latest slices are held, as many as their bytes fit within the limit
+		want, total := 0, 0
+		for j := len(lengths) - 1; j >= 0 && total+lengths[j] <= limit; j-- {
+			total += lengths[j]
+			want++
+		}
+		held := 0
+		for _, b := range p.Snapshot() {
+			held += len(b)
+		}
+		if n := p.Len(); n != want || held != total {
+			t.Fatalf("expected %d slices of %d bytes held, found %d of %d", want, total, n, held)
+		}
+	}
+}
+
+func TestWithInitialOffset_HugeOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	base := int64(math.MaxInt64 - 100)
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 3}, WithInitialOffset[int](base), WithData(0, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if first := p.FirstOffset(); first != base+1 {
+		t.Fatalf("expected first offset %d, found %d", base+1, first)
+	}
+	if v, ok := p.TryRead(base + 2); !ok || v != 2 {
+		t.Fatalf("expected 2 at offset %d, found %d, %v", base+2, v, ok)
+	}
+	if v := <-p.Read(ctx, base+3); v != 3 {
+		t.Fatalf("expected 3, found %d", v)
+	}
+	if v := <-p.Read(ctx, -3); v != 2 {
+		t.Fatalf("expected an end relative read to start at 2, found %d", v)
+	}
+}
@@ -1,66 +1,225 @@
 package pools
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type request[T any] interface {
 	Context() context.Context
-	Offset() int
-	ResetOffset(offset int)
-	PostError() chan<- error
-	PostData(data []T)
+	Offset() int64
+	ResetOffset(offset int64)
+	// ReadCount returns the number of elements delivered since the offset was set.
+	ReadCount() int
+	// PostData delivers the data, skipping any element flagged in removed, which may be nil.
+	// It returns the number of elements delivered.
+	PostData(data []T, removed []bool) int
+	// Respond sends the pool thread's response to the request. It never blocks.
+	Respond(resp response[T])
+	Response() <-chan response[T]
+	// Remaining returns the number of elements still to be delivered, or -1 if the request is unbounded.
+	Remaining() int
+	IsComplete() bool
+	// BatchSize returns the most elements delivered in a single batch, or zero if the request delivers single elements.
+	BatchSize() int
+	// Priority returns the priority of the request, greater than zero being serviced ahead of other requests.
+	Priority() int
+	// Gaps returns the channel to report jumps over evicted offsets, or nil if the request does not tolerate gaps.
+	Gaps() chan<- GapEvent
+}
+
+// response is the pool thread's reply to a request.
+type response[T any] struct {
+	data     []T           // elements to deliver
+	removed  []bool        // when not nil, flags the elements of data which are empty slots, not to be delivered
+	wait     chan struct{} // when not nil, closed once new data is fed
+	gap      *GapEvent     // when not nil, offsets jumped over
+	err      error         // error ending the request
+	complete bool          // the request has delivered all it requires
 }
 
 type requestImpl[T any] struct {
 	ctx       context.Context
 	ch        chan<- T
-	err       chan<- error
-	offset    int
+	resp      chan response[T]
+	offset    int64
 	additions int
+	remaining int // elements still to deliver, or -1 when unbounded
+	gaps      chan<- GapEvent
+	batches   chan<- []T // when not nil, data is delivered as slices, in place of ch
+	batchSize int
+	indexed   chan<- Indexed[T]              // when not nil, data is delivered with its offset, in place of ch
+	onDeliver func(ctx context.Context, t T) // when not nil, called with each element delivered
+	priority  int
+	poolDone  <-chan struct{} // closed when the pool shuts down, ending any delivery
+
+	stallTimeout time.Duration      // when greater than zero, the longest a delivery may wait to be received
+	cancel       context.CancelFunc // cancels the request context, when it stalls
 }
 
-func (rq requestImpl[T]) Context() context.Context {
+func (rq *requestImpl[T]) Context() context.Context {
 	return rq.ctx
 }
 
-func (rq requestImpl[T]) Offset() int {
-	return rq.offset + rq.additions
+func (rq *requestImpl[T]) Offset() int64 {
+	return rq.offset + int64(rq.additions)
 }
 
-func (rq requestImpl[T]) ReadCount() int {
+func (rq *requestImpl[T]) ReadCount() int {
 	return rq.additions
 }
 
-func (rq *requestImpl[T]) ResetOffset(offset int) {
+func (rq *requestImpl[T]) ResetOffset(offset int64) {
 	rq.offset = offset
 	rq.additions = 0
 }
 
-func (rq requestImpl[T]) PostError() chan<- error {
-	return rq.err
+func (rq *requestImpl[T]) Respond(resp response[T]) {
+	rq.resp <- resp
+}
+
+func (rq *requestImpl[T]) Response() <-chan response[T] {
+	return rq.resp
 }
 
-func (rq *requestImpl[T]) PostData(data []T) {
+// PostData delivers the given data to the request channel, until the request context is cancelled or the pool shuts down.
+// A bounded request is delivered no more than its remaining count, any further data is ignored.
+// Elements flagged in removed are empty slots, which are skipped over without being delivered.
+// The number of elements delivered is returned.
+func (rq *requestImpl[T]) PostData(data []T, removed []bool) int {
+	if rq.batches != nil {
+		return rq.postBatch(data, removed)
+	}
 	var count int
-	for _, t := range data {
-		select {
-		case <-rq.Context().Done():
-			return
-		case rq.ch <- t:
-			count++
+	for i, t := range data {
+		if rq.remaining == 0 {
+			break
+		}
+		if removed != nil && removed[i] {
+			rq.additions++
+			continue
+		}
+		if !rq.postElement(t) {
+			break
 		}
+		count++
+		rq.delivered(t)
+		rq.additions++
+		if rq.remaining > 0 {
+			rq.remaining--
+		}
+	}
+	return count
+}
+
+// postElement delivers the given element, the next of the request, to the data channel, or with its offset,
+// to the indexed channel. false is returned if the element was not delivered.
+func (rq *requestImpl[T]) postElement(t T) bool {
+	if rq.indexed != nil {
+		return post(rq, rq.indexed, Indexed[T]{Offset: rq.Offset(), Value: t})
+	}
+	return post(rq, rq.ch, t)
+}
+
+// delivered passes the element, having been delivered, to any delivery hook, with the request context.
+func (rq *requestImpl[T]) delivered(t T) {
+	if rq.onDeliver != nil {
+		rq.onDeliver(rq.ctx, t)
 	}
-	rq.additions += count
 }
 
-func (rq requestImpl[T]) IsOffsetValid() bool {
+// postBatch delivers the given data as a single slice to the batch channel, without the elements flagged in removed.
+// The number of elements in the batch is returned, or zero if it was not delivered.
+func (rq *requestImpl[T]) postBatch(data []T, removed []bool) int {
+	batch := data[:0] // data is a copy, owned by the request, so is filtered in place
+	n := 0            // number of elements of data covered by the batch
+	for ; n < len(data) && (rq.remaining < 0 || len(batch) < rq.remaining); n++ {
+		if removed == nil || !removed[n] {
+			batch = append(batch, data[n])
+		}
+	}
+	if len(batch) == 0 {
+		rq.additions += n
+		return 0
+	}
+	var hooked []T
+	if rq.onDeliver != nil {
+		// the batch belongs to the reader once sent, so the hook is given a copy
+		hooked = append(hooked, batch...)
+	}
+	if !post(rq, rq.batches, batch) {
+		return 0
+	}
+	for _, t := range hooked {
+		rq.delivered(t)
+	}
+	rq.additions += n
+	if rq.remaining > 0 {
+		rq.remaining -= len(batch)
+	}
+	return len(batch)
+}
+
+// post sends the value on the given channel of the request, returning false if the value could not be sent, as the
+// request context is cancelled or the pool shuts down. If the request has a stall timeout, and the send is not received
+// within it, the request is cancelled.
+func post[T any, V any](rq *requestImpl[T], ch chan<- V, v V) bool {
+	var stall <-chan time.Time
+	if rq.stallTimeout > 0 {
+		timer := time.NewTimer(rq.stallTimeout)
+		defer timer.Stop()
+		stall = timer.C
+	}
+	select {
+	case <-rq.Context().Done():
+		return false
+	case <-rq.poolDone:
+		return false
+	case <-stall:
+		rq.cancel()
+		return false
+	case ch <- v:
+		return true
+	}
+}
+
+func (rq *requestImpl[T]) Remaining() int {
+	return rq.remaining
+}
+
+func (rq *requestImpl[T]) IsComplete() bool {
+	return rq.remaining == 0
+}
+
+func (rq *requestImpl[T]) BatchSize() int {
+	return rq.batchSize
+}
+
+func (rq *requestImpl[T]) Priority() int {
+	return rq.priority
+}
+
+func (rq *requestImpl[T]) Gaps() chan<- GapEvent {
+	return rq.gaps
+}
+
+func (rq *requestImpl[T]) IsOffsetValid() bool {
 	return rq.offset >= 0
 }
 
-func newRequest[T any](ctx context.Context, out chan<- T, err chan<- error, offset int) request[T] {
+// newRequest creates a new request for the given offset. If limit is greater than zero, the request
+// is complete once limit elements have been delivered, otherwise it is unbounded.
+func newRequest[T any](ctx context.Context, out chan<- T, offset int64, limit int, gaps chan<- GapEvent) *requestImpl[T] {
+	remaining := -1
+	if limit > 0 {
+		remaining = limit
+	}
 	return &requestImpl[T]{
-		ctx:    ctx,
-		ch:     out,
-		err:    err,
-		offset: offset,
+		ctx:       ctx,
+		ch:        out,
+		resp:      make(chan response[T], 1), // a request is only ever submitted once before its response is received
+		offset:    offset,
+		remaining: remaining,
+		gaps:      gaps,
 	}
 }
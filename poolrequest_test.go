@@ -0,0 +1,41 @@
+package pools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequest_PostDataHonoursLimit(t *testing.T) {
+	out := make(chan int, 100)
+	rq := newRequest[int](context.Background(), out, 0, 3, nil)
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+	if n := rq.PostData(data, nil); n != 3 {
+		t.Fatalf("expected 3 elements posted, found %d", n)
+	}
+	if len(out) != 3 {
+		t.Fatalf("expected 3 elements delivered, found %d", len(out))
+	}
+	if !rq.IsComplete() || rq.Offset() != 3 {
+		t.Fatalf("expected the request to be complete at offset 3, found offset %d", rq.Offset())
+	}
+	if n := rq.PostData(data, nil); n != 0 || len(out) != 3 {
+		t.Fatalf("expected a complete request to post nothing more, found %d", n)
+	}
+}
+
+func TestRequest_PostDataSkipsRemoved(t *testing.T) {
+	out := make(chan int, 10)
+	rq := newRequest[int](context.Background(), out, 0, 2, nil)
+	if n := rq.PostData([]int{0, 1, 2, 3}, []bool{false, true, false, false}); n != 2 {
+		t.Fatalf("expected 2 elements posted, found %d", n)
+	}
+	if a, b := <-out, <-out; a != 0 || b != 2 {
+		t.Fatalf("expected [0 2], found [%d %d]", a, b)
+	}
+	if rq.Offset() != 3 {
+		t.Fatalf("expected the removed slot to be passed over, to offset 3, found %d", rq.Offset())
+	}
+}
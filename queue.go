@@ -0,0 +1,101 @@
+package pools
+
+import (
+	"context"
+	"sort"
+)
+
+// NewQueue creates a new Pool which hands each element to just one of its readers, as a work queue of competing readers.
+// Every read receives the next element not yet handed to any other read, regardless of the offset it was given.
+// Elements are handed out one at a time, in the order they were fed, and an element is consumed once its reader has
+// received it, so an OverflowBlock policy blocks feeds only while elements are waiting for, or being sent to, a reader.
+// An element handed to a reader which ends before receiving it, such as by its context being cancelled, is handed
+// to the next reader, ahead of the elements not yet handed out.
+// Any options are applied as with NewPoolWithOptions. An error is returned if the policy is not valid.
+func NewQueue[T any](ctx context.Context, policy Policy, opts ...Option[T]) (Pool[T], error) {
+	opts = append(opts, func(p *pool[T]) {
+		p.queue = &queueState[T]{handouts: map[request[T]]handout{}}
+	})
+	return NewPoolWithOptions(ctx, policy, opts...)
+}
+
+// queueState tracks the elements a queue has handed to its readers. It is owned by the pool thread.
+type queueState[T any] struct {
+	next     int64                  // offset of the next element not yet handed out
+	handouts map[request[T]]handout // the element last handed to each reader, until it is known to have been received
+	returned []int64                // offsets of elements handed to readers which ended before receiving them, in order
+}
+
+// handout is an element handed to a reader.
+type handout struct {
+	offset    int64
+	readCount int // ReadCount of the request as the element was handed out, which increases once it is received
+}
+
+// acknowledge settles the element last handed to the request, which has been received if the request has since read
+// more, otherwise it is returned, to be handed out again.
+func (q *queueState[T]) acknowledge(rq request[T]) {
+	h, ok := q.handouts[rq]
+	if !ok {
+		return
+	}
+	delete(q.handouts, rq)
+	if rq.ReadCount() == h.readCount {
+		q.returned = append(q.returned, h.offset)
+		sort.Slice(q.returned, func(i, j int) bool {
+			return q.returned[i] < q.returned[j]
+		})
+	}
+}
+
+// take returns the offset of the next element to hand out to the given request, preferring any which were returned.
+// Elements removed before being handed out are passed over. false is returned if there is no element to hand out.
+func (q *queueState[T]) take(data *offsetData[T], rq request[T]) (int64, bool) {
+	offset, ok := q.nextOffset(data)
+	if ok {
+		q.handouts[rq] = handout{offset: offset, readCount: rq.ReadCount()}
+	}
+	return offset, ok
+}
+
+func (q *queueState[T]) nextOffset(data *offsetData[T]) (int64, bool) {
+	for len(q.returned) > 0 {
+		offset := q.returned[0]
+		q.returned = q.returned[1:]
+		if _, ok := data.Get(offset); ok {
+			return offset, true
+		}
+	}
+	if q.next < data.Offset() {
+		q.next = data.Offset()
+	}
+	for q.next < data.NextOffset() && data.IsRemoved(q.next) {
+		q.next++
+	}
+	if q.next >= data.NextOffset() {
+		return 0, false
+	}
+	q.next++
+	return q.next - 1, true
+}
+
+// consumed returns the offset below which every element has been received by a reader.
+func (q *queueState[T]) consumed() int64 {
+	offset := q.next
+	for _, h := range q.handouts {
+		if h.offset < offset {
+			offset = h.offset
+		}
+	}
+	if len(q.returned) > 0 && q.returned[0] < offset {
+		offset = q.returned[0]
+	}
+	return offset
+}
+
+// reset restarts the queue at the given offset, forgetting the elements handed out before.
+func (q *queueState[T]) reset(offset int64) {
+	q.next = offset
+	q.handouts = map[request[T]]handout{}
+	q.returned = nil
+}
@@ -0,0 +1,85 @@
+package pools
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewQueue_NoDuplicateDelivery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q, err := NewQueue[int](ctx, Policy{Count: 100, Overflow: OverflowBlock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const items = 1000
+	var mu sync.Mutex
+	seen := make(map[int]int)
+	all := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func(r <-chan int) {
+			for v := range r {
+				mu.Lock()
+				seen[v]++
+				if seen[v] == 1 && len(seen) == items {
+					close(all)
+				}
+				mu.Unlock()
+			}
+		}(q.Read(ctx, 0))
+	}
+	for i := 0; i < items; i++ {
+		if err := q.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-all:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected every element to be read")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for v, n := range seen {
+		if n != 1 {
+			t.Fatalf("expected %d to be delivered once, delivered %d times", v, n)
+		}
+	}
+}
+
+func TestNewQueue_CancelledReaderReturnsElement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q, err := NewQueue[int](ctx, Policy{Count: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rctx, rcancel := context.WithCancel(ctx)
+	_ = q.Read(rctx, 0)
+	if err := q.Append(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	// wait for the element to be handed to the reader, which never receives it
+	for handedOut := false; !handedOut; {
+		q.(*pool[int]).query(func(data *offsetData[int]) {
+			handedOut = len(q.(*pool[int]).queue.handouts) == 1
+		})
+	}
+	rcancel()
+	// the channel is left unread, so the element can not be delivered as the read ends
+	for ended := false; !ended; {
+		q.(*pool[int]).query(func(data *offsetData[int]) {
+			ended = len(q.(*pool[int]).readers) == 0
+		})
+	}
+	select {
+	case v := <-q.Read(ctx, 0):
+		if v != 1 {
+			t.Fatalf("expected 1, found %d", v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the element to be handed to the next reader")
+	}
+}
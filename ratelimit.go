@@ -0,0 +1,51 @@
+package pools
+
+import (
+	"math"
+	"time"
+)
+
+// rateLimiter is a token bucket, limiting the rate elements are accepted, allowing a burst of up to one second's worth.
+type rateLimiter struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued, at the given rate, since the last refill.
+func (rl *rateLimiter) refill(rate float64, now time.Time) {
+	if !rl.last.IsZero() {
+		rl.tokens += now.Sub(rl.last).Seconds() * rate
+	} else {
+		rl.tokens = math.Max(rate, 1)
+	}
+	rl.tokens = math.Min(rl.tokens, math.Max(rate, 1))
+	rl.last = now
+}
+
+// Allow checks if an element may be accepted at the given rate, without taking a token.
+func (rl *rateLimiter) Allow(rate float64, now time.Time) bool {
+	rl.refill(rate, now)
+	return rl.tokens >= 1
+}
+
+// Take takes a token, returning false if none are available at the given rate.
+func (rl *rateLimiter) Take(rate float64, now time.Time) bool {
+	if !rl.Allow(rate, now) {
+		return false
+	}
+	rl.tokens--
+	return true
+}
+
+// Delay returns the time until the next token is available at the given rate.
+func (rl *rateLimiter) Delay(rate float64) time.Duration {
+	if rl.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - rl.tokens) / rate * float64(time.Second))
+}
+
+// Spend takes a token, whether available or not, leaving the limiter in debt until enough tokens have accrued.
+func (rl *rateLimiter) Spend() {
+	rl.tokens--
+}
@@ -0,0 +1,91 @@
+package pools
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Take(t *testing.T) {
+	clock := newFakeClock()
+	var rl rateLimiter
+	var taken int
+	for i := 0; i < 20; i++ {
+		if rl.Take(5, clock.Now()) {
+			taken++
+		}
+	}
+	if taken != 5 {
+		t.Fatalf("expected a burst of 5, took %d", taken)
+	}
+	clock.Advance(time.Second / 5)
+	if !rl.Take(5, clock.Now()) || rl.Take(5, clock.Now()) {
+		t.Fatal("expected a single token after a fifth of a second")
+	}
+	if d := rl.Delay(5); d != time.Second/5 {
+		t.Fatalf("expected the next token in %v, found %v", time.Second/5, d)
+	}
+}
+
+func TestPolicy_MaxRatePerSec(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := newFakeClock()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 1000, MaxRatePerSec: 10, Overflow: OverflowDropNewest},
+		withClock[int](clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := func(n int) {
+		for i := 0; i < n; i++ {
+			if err := p.Append(ctx, i); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	feed(50)
+	if n := p.Len(); n != 10 {
+		t.Fatalf("expected a burst of 10 accepted, found %d", n)
+	}
+	clock.Advance(500 * time.Millisecond)
+	feed(50)
+	if n := p.Len(); n != 15 {
+		t.Fatalf("expected 5 more accepted after half a second, found %d", n-10)
+	}
+	// the unused rate accrues for no more than a second
+	clock.Advance(10 * time.Second)
+	feed(50)
+	if n := p.Len(); n != 25 {
+		t.Fatalf("expected 10 more accepted after ten seconds, found %d", n-15)
+	}
+	if stats := p.Stats(); stats.Fed != 150 || stats.Evicted != 125 {
+		t.Fatalf("expected 125 of 150 fed to be rejected, found %+v", stats)
+	}
+}
+
+func TestPolicy_MaxRatePerSecRejectsToOverflowSink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := newFakeClock()
+	sink := make(chan int, 10)
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 100, MaxRatePerSec: 2, Overflow: OverflowDropNewest},
+		withClock[int](clock.Now), WithOverflowSink[int](sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var rejected []int
+	for len(sink) > 0 {
+		rejected = append(rejected, <-sink)
+	}
+	if len(rejected) != 3 || rejected[0] != 2 || rejected[2] != 4 {
+		t.Fatalf("expected the elements beyond the rate, [2 3 4], sent to the sink, found %v", rejected)
+	}
+}
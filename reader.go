@@ -0,0 +1,54 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// NewReader creates a Reader which reads the chunks of the given pool, starting at the given offset, as a single stream of bytes.
+// The Reader returns io.EOF once the context is cancelled or the pool shuts down. Any other error ending the read,
+// such as ErrOffsetEvicted when the offset has been evicted, is returned in its place.
+func NewReader(ctx context.Context, p Pool[[]byte], offset int64) io.Reader {
+	ch, errc := p.ReadWithErr(ctx, offset)
+	return &poolReader{
+		ch:   ch,
+		errc: errc,
+	}
+}
+
+type poolReader struct {
+	ch   <-chan []byte
+	errc <-chan error
+	err  error  // the error which ended the read, once the data channel has closed
+	buf  []byte // remainder of the current chunk
+}
+
+func (r *poolReader) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		chunk, ok := <-r.ch
+		if !ok {
+			r.err = readerErr(<-r.errc)
+			return 0, r.err
+		}
+		r.buf = chunk
+	}
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// readerErr returns the error a Reader ends with, given the error which ended its read.
+// A read ended by its context or the pool shutting down is the end of the stream.
+func readerErr(err error) error {
+	if err == nil || errors.Is(err, ErrPoolClosed) || errors.Is(err, ErrComplete) {
+		return io.EOF
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReader_ReadAll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[[]byte](ctx, Policy{Count: 10}, []byte("hello"), []byte(" "), []byte("world"))
+	rctx, rcancel := context.WithCancel(ctx)
+	r := NewReader(rctx, p, 0)
+	b := make([]byte, len("hello world"))
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello world" {
+		t.Fatalf("expected %q, found %q", "hello world", b)
+	}
+	// cancelling the reader ends it, so a read to the end completes
+	rcancel()
+	if rest, err := io.ReadAll(r); err != nil || len(rest) != 0 {
+		t.Fatalf("expected nothing more once cancelled, found %q, %v", rest, err)
+	}
+}
+
+func TestReader_SmallBuffer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[[]byte](ctx, Policy{Count: 10}, []byte("hello"), []byte("world"))
+	r := NewReader(ctx, p, 0)
+	var got []byte
+	buf := make([]byte, 3)
+	for len(got) < 10 {
+		n, err := r.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > len(buf) {
+			t.Fatalf("expected no more than %d bytes, found %d", len(buf), n)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "helloworld" {
+		t.Fatalf("expected %q, found %q", "helloworld", got)
+	}
+	cancel()
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatalf("expected the reader to end with io.EOF once cancelled, found %v", err)
+	}
+}
+
+func TestReader_OffsetEvicted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[[]byte](ctx, Policy{Count: 2}, []byte("a"), []byte("b"), []byte("c"))
+	r := NewReader(ctx, p, 0)
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrOffsetEvicted) {
+		t.Fatalf("expected ErrOffsetEvicted, found %v", err)
+	}
+	// the error remains once the read has ended
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, ErrOffsetEvicted) {
+		t.Fatalf("expected ErrOffsetEvicted, found %v", err)
+	}
+}
+
+func TestReader_PoolShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := MustNewPool[[]byte](ctx, Policy{Count: 10}, []byte("abc"))
+	r := NewReader(context.Background(), p, 0)
+	b := make([]byte, 3)
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if rest, err := io.ReadAll(r); err != nil || len(rest) != 0 {
+		t.Fatalf("expected the reader to end with io.EOF once the pool shuts down, found %q, %v", rest, err)
+	}
+}
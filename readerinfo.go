@@ -0,0 +1,19 @@
+package pools
+
+import "time"
+
+// ReaderInfo describes an active reader of a pool.
+type ReaderInfo struct {
+	// StartOffset is the offset the reader started reading at, or, once the pool is reset, started again at.
+	StartOffset int64
+	// CurrentOffset is the offset the reader last requested, all elements before it having been delivered.
+	CurrentOffset int64
+	// Age is the time since the reader started.
+	Age time.Duration
+}
+
+// readerStart records where and when a reader started.
+type readerStart struct {
+	offset int64
+	at     time.Time
+}
@@ -0,0 +1,58 @@
+package pools
+
+import "context"
+
+// ReadOnlyPool is a view of a Pool which may only be read, so may be handed to code which must not feed, or close,
+// the pool. Its methods behave as those of the Pool of the same name.
+type ReadOnlyPool[T any] interface {
+	Policy() Policy
+	Read(ctx context.Context, offset int64) <-chan T
+	ReadWithErr(ctx context.Context, offset int64) (<-chan T, <-chan error)
+	Stats() PoolStats
+	Len() int
+	FirstOffset() int64
+	NextOffset() int64
+	Snapshot() []T
+	WaitForClose()
+}
+
+// readOnly wraps a pool, rather than returning it as a ReadOnlyPool, so the view can not be asserted back to a Pool.
+type readOnly[T any] struct {
+	p Pool[T]
+}
+
+func (r readOnly[T]) Policy() Policy {
+	return r.p.Policy()
+}
+
+func (r readOnly[T]) Read(ctx context.Context, offset int64) <-chan T {
+	return r.p.Read(ctx, offset)
+}
+
+func (r readOnly[T]) ReadWithErr(ctx context.Context, offset int64) (<-chan T, <-chan error) {
+	return r.p.ReadWithErr(ctx, offset)
+}
+
+func (r readOnly[T]) Stats() PoolStats {
+	return r.p.Stats()
+}
+
+func (r readOnly[T]) Len() int {
+	return r.p.Len()
+}
+
+func (r readOnly[T]) FirstOffset() int64 {
+	return r.p.FirstOffset()
+}
+
+func (r readOnly[T]) NextOffset() int64 {
+	return r.p.NextOffset()
+}
+
+func (r readOnly[T]) Snapshot() []T {
+	return r.p.Snapshot()
+}
+
+func (r readOnly[T]) WaitForClose() {
+	r.p.WaitForClose()
+}
@@ -0,0 +1,66 @@
+package pools
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReadOnly_CanNotFeedOrClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ro any = MustNewPool[int](ctx, Policy{Count: 5}).ReadOnly()
+	if _, ok := ro.(Pool[int]); ok {
+		t.Fatal("expected the read-only view not to be a Pool")
+	}
+	if _, ok := ro.(interface {
+		Append(ctx context.Context, t int) error
+	}); ok {
+		t.Fatal("expected the read-only view not to Append")
+	}
+	if _, ok := ro.(interface {
+		Feed(ctx context.Context, ch <-chan int) <-chan struct{}
+	}); ok {
+		t.Fatal("expected the read-only view not to Feed")
+	}
+	if _, ok := ro.(interface{ Close() }); ok {
+		t.Fatal("expected the read-only view not to Close")
+	}
+}
+
+func TestReadOnly_ReflectsFeeds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 3}, 0)
+	ro := p.ReadOnly()
+	r := ro.Read(ctx, 0)
+	for i := 1; i < 5; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := ro.Len(); n != 3 {
+		t.Fatalf("expected the view to hold 3 elements, found %d", n)
+	}
+	if s := ro.Snapshot(); len(s) != 3 || s[0] != 2 || s[2] != 4 {
+		t.Fatalf("expected [2 3 4], found %v", s)
+	}
+	if first, next := ro.FirstOffset(), ro.NextOffset(); first != 2 || next != 5 {
+		t.Fatalf("expected offsets 2 to 5, found %d to %d", first, next)
+	}
+	if v := <-r; v != 0 {
+		t.Fatalf("expected the view to read 0, found %d", v)
+	}
+
+	p.Close()
+	closed := make(chan struct{})
+	go func() {
+		ro.WaitForClose()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the view to see the pool close")
+	}
+}
@@ -0,0 +1,37 @@
+package pools
+
+// RetentionPolicy decides which elements a pool keeps, in place of the Policy Size, Count and MaxAge.
+// Evict is called on the pool thread each time elements are fed, or the pool's policy is applied,
+// so should be fast, and must not call the methods of the pool. A panic in Evict is recovered and logged,
+// leaving the pool holding whatever elements were not yet removed.
+type RetentionPolicy[T any] interface {
+	// Evict removes the elements the pool should no longer hold from the given data.
+	Evict(data RetentionData[T])
+}
+
+// RetentionData is the view of the elements of a pool given to a RetentionPolicy.
+// It is only valid for the duration of the Evict call it is given to.
+type RetentionData[T any] interface {
+	// Offset returns the offset of the first element held.
+	Offset() int64
+	// NextOffset returns the offset following the last element held.
+	NextOffset() int64
+	// LiveLength returns the number of elements held.
+	LiveLength() int
+	// Size returns the byte size of the elements held.
+	Size() uint64
+	// Get returns the element at the given offset, false if the offset is not held, or its element has been removed.
+	Get(offset int64) (T, bool)
+	// Remove removes the element at the given offset, leaving the offsets of the following elements unchanged.
+	Remove(offset int64)
+	// TrimToLength removes the oldest elements, leaving, at most, the given count of the most recent offsets.
+	TrimToLength(count int)
+}
+
+// WithRetention sets a RetentionPolicy to decide which elements the pool keeps, in place of the Policy Size, Count
+// and MaxAge. A pool with a RetentionPolicy may be given a Policy which is otherwise unconstrained.
+func WithRetention[T any](retention RetentionPolicy[T]) Option[T] {
+	return func(p *pool[T]) {
+		p.retention = retention
+	}
+}
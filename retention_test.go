@@ -0,0 +1,60 @@
+package pools
+
+import (
+	"context"
+	"testing"
+)
+
+// keepMatching retains every element matching keep, removing the oldest of the others beyond max.
+type keepMatching struct {
+	keep func(int) bool
+	max  int
+}
+
+func (r keepMatching) Evict(data RetentionData[int]) {
+	var others []int64
+	for offset := data.Offset(); offset < data.NextOffset(); offset++ {
+		if v, ok := data.Get(offset); ok && !r.keep(v) {
+			others = append(others, offset)
+		}
+	}
+	for len(others) > r.max {
+		data.Remove(others[0])
+		others = others[1:]
+	}
+}
+
+func TestWithRetention_KeepsMatching(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	retention := keepMatching{keep: func(i int) bool { return i%10 == 0 }, max: 3}
+	p, err := NewPoolWithOptions[int](ctx, Policy{}, WithRetention[int](retention))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 25; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []int{0, 10, 20, 22, 23, 24}
+	s := p.Snapshot()
+	if len(s) != len(want) {
+		t.Fatalf("expected %v, found %v", want, s)
+	}
+	for i, v := range want {
+		if s[i] != v {
+			t.Fatalf("expected %v, found %v", want, s)
+		}
+	}
+	// readers pass over the removed elements
+	r := p.ReadN(ctx, 0, len(want))
+	for _, v := range want {
+		if got := <-r; got != v {
+			t.Fatalf("expected to read %d, found %d", v, got)
+		}
+	}
+	if stats := p.Stats(); stats.Evicted != 19 {
+		t.Fatalf("expected 19 elements evicted, found %d", stats.Evicted)
+	}
+}
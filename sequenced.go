@@ -0,0 +1,43 @@
+package pools
+
+import "context"
+
+// Sequenced is an element tagged with the source it was fed from and its position in that source.
+type Sequenced[T any] struct {
+	Source string
+	Seq    int
+	Value  T
+}
+
+// FeedOrdered feeds all the elements received from the given channel into a pool of Sequenced elements.
+// Each element is tagged with the given source name and its zero based sequence in the channel, so that readers of
+// a pool fed from many sources may reconstruct the order of each source.
+func FeedOrdered[T any](ctx context.Context, p Pool[Sequenced[T]], source string, ch <-chan T) <-chan struct{} {
+	seqCh := make(chan Sequenced[T])
+	done := p.Feed(ctx, seqCh)
+	go func() {
+		defer close(seqCh)
+		var seq int
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case t, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				case seqCh <- Sequenced[T]{Source: source, Seq: seq, Value: t}:
+					seq++
+				}
+			}
+		}
+	}()
+	return done
+}
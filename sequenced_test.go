@@ -0,0 +1,43 @@
+package pools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestFeedOrdered_PerSourceOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const perSource = 100
+	p := MustNewPool[Sequenced[int]](ctx, Policy{Count: 3 * perSource})
+	for s := 0; s < 3; s++ {
+		ch := make(chan int)
+		FeedOrdered[int](ctx, p, fmt.Sprintf("source-%d", s), ch)
+		go func() {
+			defer close(ch)
+			for i := 0; i < perSource; i++ {
+				ch <- i
+			}
+		}()
+	}
+	if err := p.WaitForOffset(ctx, 3*perSource-1); err != nil {
+		t.Fatal(err)
+	}
+
+	next := map[string]int{}
+	for _, e := range p.Snapshot() {
+		if e.Seq != next[e.Source] || e.Value != e.Seq {
+			t.Fatalf("expected %s element %d, found sequence %d of value %d", e.Source, next[e.Source], e.Seq, e.Value)
+		}
+		next[e.Source]++
+	}
+	if len(next) != 3 {
+		t.Fatalf("expected 3 sources, found %d", len(next))
+	}
+	for source, n := range next {
+		if n != perSource {
+			t.Fatalf("expected %d elements from %s, found %d", perSource, source, n)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package pools
+
+import "reflect"
+
+// Sizer may be implemented by an element type to report its own byte size, when applying the Policy Size.
+// It allows elements referring to data, such as slices or maps, to include that data in their size.
+// A sizer set with WithSizer takes precedence over the element's own Sizer.
+// Should PoolSize panic, the panic is logged and the element measured as zero.
+type Sizer interface {
+	PoolSize() uint64
+}
+
+// isSizer checks if the element type T implements Sizer.
+func isSizer[T any]() bool {
+	return reflect.TypeOf((*T)(nil)).Elem().Implements(reflect.TypeOf((*Sizer)(nil)).Elem())
+}
@@ -0,0 +1,29 @@
+package pools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// snapshot is the serialised form of the contents of a pool.
+type snapshot[T any] struct {
+	Offset int64 `json:"offset"`
+	Data   []T   `json:"data"`
+}
+
+// LoadPool creates a new Pool containing the contents of a snapshot, as created by MarshalSnapshot.
+// The elements keep the offsets they held in the snapshotted pool, so readers may resume from a recorded offset.
+// Any options are applied as with NewPoolWithOptions, however any initial data option is replaced by the snapshot.
+// An error is returned if the snapshot can not be unmarshalled or the policy is not valid.
+func LoadPool[T any](ctx context.Context, policy Policy, data []byte, opts ...Option[T]) (Pool[T], error) {
+	var snap snapshot[T]
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("pool snapshot not loaded as %w", err)
+	}
+	if snap.Offset < 0 {
+		return nil, fmt.Errorf("pool snapshot not loaded as offset %d is negative", snap.Offset)
+	}
+	opts = append(opts, WithData(snap.Data...), WithInitialOffset[T](snap.Offset))
+	return NewPoolWithOptions(ctx, policy, opts...)
+}
@@ -0,0 +1,43 @@
+package pools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[string](ctx, Policy{Count: 3})
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		if err := p.Append(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := p.MarshalSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPool[string](ctx, Policy{Count: 3}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, next := loaded.FirstOffset(), loaded.NextOffset(); first != 2 || next != 5 {
+		t.Fatalf("expected offsets 2 to 5, found %d to %d", first, next)
+	}
+	for offset, want := range map[int64]string{2: "c", 3: "d", 4: "e"} {
+		if v := <-loaded.Read(ctx, offset); v != want {
+			t.Fatalf("expected %q at offset %d, found %q", want, offset, v)
+		}
+	}
+}
+
+func TestLoadPool_InvalidSnapshot(t *testing.T) {
+	if _, err := LoadPool[int](context.Background(), Policy{Count: 3}, []byte("not json")); err == nil {
+		t.Fatal("expected an invalid snapshot to fail to load")
+	}
+	if _, err := LoadPool[int](context.Background(), Policy{Count: 3}, []byte(`{"offset":-1,"data":[1]}`)); err == nil {
+		t.Fatal("expected a negative offset to fail to load")
+	}
+}
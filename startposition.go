@@ -0,0 +1,15 @@
+package pools
+
+// StartPosition selects where a ReadFrom begins reading.
+type StartPosition int
+
+const (
+	// FromEarliest reads from the first available element, ending the read if its offset is evicted,
+	// in the same way as reading from offset -1.
+	FromEarliest StartPosition = iota
+	// FromLatest ignores the existing elements, reading only elements fed after the read began, as ReadLatest.
+	FromLatest
+	// FromEarliestFollow reads from the first available element, however, should its offset be evicted,
+	// the read jumps forward to the new first available element, rather than ending.
+	FromEarliestFollow
+)
@@ -0,0 +1,25 @@
+package pools
+
+// PoolStats reports the number of elements which have passed through a pool.
+type PoolStats struct {
+	// Length is the number of elements held in the pool. Once the pool has shutdown, it is the number held as the
+	// pool shut down.
+	Length int
+	// Fed is the total number of elements fed into the pool, including any initial data.
+	Fed int
+	// Evicted is the total number of elements removed from the pool by its Policy, or in a keyed pool,
+	// replaced by a later element with the same key. Once the pool has shutdown, it includes the elements evicted at shutdown.
+	Evicted int
+	// Delivered is the total number of elements delivered, across all readers.
+	Delivered int
+	// FirstOffset is the offset of the earliest element held in the pool.
+	FirstOffset int64
+	// NextOffset is the offset the next element fed into the pool will occupy.
+	NextOffset int64
+	// Readers is the number of active readers.
+	Readers int
+	// Resets is the number of times the pool has been Reset, restarting its offsets at zero.
+	Resets int
+	// SinkDropped is the number of unread elements dropped, rather than sent to the overflow sink, as the sink was full.
+	SinkDropped int
+}
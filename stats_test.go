@@ -0,0 +1,171 @@
+package pools
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestStats_KeyedReplacementIsEvicted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewKeyedPool[int, int](ctx, Policy{Count: 10}, func(i int) int { return i % 2 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := p.Stats()
+	if stats.Fed != 5 || stats.Evicted != 3 || stats.Length != 2 {
+		t.Fatalf("expected 5 fed, 3 evicted, 2 held, found %+v", stats)
+	}
+}
+
+func TestStats_DeliveredSkipsRemovedSlots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := NewKeyedPool[int, int](ctx, Policy{Count: 10}, func(i int) int { return i })
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the second 10 replaces the first, leaving an empty slot between 20 and itself
+	for _, i := range []int{20, 10, 10} {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ReadAll returns once the reader has counted its deliveries and detached
+	all, err := p.ReadAll(ctx, -1, 2)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("expected 2 elements, found %v, %v", all, err)
+	}
+	if n := p.Stats().Delivered; n != 2 {
+		t.Fatalf("expected 2 delivered, found %d", n)
+	}
+}
+
+func TestReaderLags_UnstartedReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 10}, 0, 1, 2, 3)
+	// never received from, so the reader holds its relative offset until its first request is serviced
+	_ = p.Read(ctx, -2)
+	if lags := p.ReaderLags(); len(lags) != 1 || lags[0] != 1 {
+		t.Fatalf("expected the reader to lag by 1, found %v", lags)
+	}
+}
+
+func TestWaitForCloseStats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 5}, 0, 1, 2)
+	feed := make(chan int, 10)
+	for i := 3; i < 13; i++ {
+		feed <- i
+	}
+	p.Feed(ctx, feed)
+	waitForFed(t, p, 13)
+	var read int
+	for range p.ReadN(ctx, -1, 3) {
+		read++
+	}
+	if read != 3 {
+		t.Fatalf("expected 3 elements, found %d", read)
+	}
+	p.Close()
+	stats := p.WaitForCloseStats()
+	// the 5 elements held at shutdown are evicted with the 8 removed by the policy
+	if stats.Fed != 13 || stats.Evicted != 13 || stats.Length != 5 || stats.Delivered != 3 {
+		t.Fatalf("expected 13 fed, 13 evicted, 5 held at shutdown, 3 delivered, found %+v", stats)
+	}
+}
+
+func TestStats_Counters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 4}, 0, 1)
+	if stats := p.Stats(); stats.Fed != 2 || stats.Length != 2 || stats.NextOffset != 2 {
+		t.Fatalf("expected 2 fed and held, found %+v", stats)
+	}
+	for i := 2; i < 6; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := p.Stats()
+	if stats.Fed != 6 || stats.Evicted != 2 || stats.Length != 4 || stats.FirstOffset != 2 || stats.NextOffset != 6 {
+		t.Fatalf("expected 6 fed, 2 evicted, 4 held from offset 2, found %+v", stats)
+	}
+
+	r := p.ReadN(ctx, -1, 2)
+	_ = p.Read(ctx, ReadLatest) // remains active, waiting for the next element
+	// the channel closes once the reader has counted its deliveries and detached
+	for range r {
+	}
+	stats = p.Stats()
+	if stats.Delivered != 2 || stats.Readers != 1 {
+		t.Fatalf("expected 2 delivered and 1 active reader, found %+v", stats)
+	}
+}
+
+func TestReaderLags_SlowReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[int](ctx, Policy{Count: 100})
+	// the reader never receives, so stays at the first element
+	_ = p.Read(ctx, ReadLatest)
+	for i := 0; i < 20; i++ {
+		if err := p.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+		lags := p.ReaderLags()
+		// the lag grows with each element fed
+		if len(lags) != 1 || lags[0] != i+1 {
+			t.Fatalf("expected a lag of %d, found %v", i+1, lags)
+		}
+	}
+}
+
+func TestReaders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := newFakeClock()
+	p, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithData(0, 1, 2, 3, 4), withClock[int](clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rctx, rcancel := context.WithCancel(ctx)
+	defer rcancel()
+	// the first reader receives one element, then holds the rest of its first batch
+	first := p.Read(rctx, 1)
+	<-first
+	clock.Advance(time.Minute)
+	_ = p.Read(rctx, ReadLatest)
+	clock.Advance(time.Minute)
+
+	infos := p.Readers()
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 readers, found %+v", infos)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].StartOffset < infos[j].StartOffset })
+	if want := (ReaderInfo{StartOffset: 1, CurrentOffset: 1, Age: 2 * time.Minute}); infos[0] != want {
+		t.Fatalf("expected %+v, found %+v", want, infos[0])
+	}
+	if want := (ReaderInfo{StartOffset: 5, CurrentOffset: 5, Age: time.Minute}); infos[1] != want {
+		t.Fatalf("expected %+v, found %+v", want, infos[1])
+	}
+
+	rcancel()
+	deadline := time.After(2 * time.Second)
+	for len(p.Readers()) > 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the cancelled readers to be removed, found %+v", p.Readers())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
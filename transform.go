@@ -0,0 +1,107 @@
+package pools
+
+import (
+	"context"
+	"sync"
+)
+
+// Map creates a new pool, governed by the given policy, fed with each element of the source pool converted by fn.
+// Elements are read from the first available in the source, and fed in the same order.
+// Should the source evict elements before they are read, those elements are skipped.
+// An element for which fn panics is dropped, the panic being logged to the Logger of the source pool.
+// Once the source pool shuts down, the new pool is closed as with CloseAndDrain, so its readers are first delivered
+// the elements it holds. It is closed immediately should the context be cancelled.
+// An error is returned if the policy is not valid.
+func Map[A, B any](ctx context.Context, src Pool[A], policy Policy, fn func(A) B) (Pool[B], error) {
+	dst, err := NewPool[B](ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	go transform(ctx, src, dst, "Map", func(a A) (B, bool) {
+		return fn(a), true
+	})
+	return dst, nil
+}
+
+// Filter creates a new pool, governed by the given policy, fed with the elements of the source pool which match pred.
+// An element for which pred panics is dropped, the panic being logged to the Logger of the source pool.
+// As with Map, the new pool is drained and closed once the source pool shuts down, or closed once the context is
+// cancelled.
+// An error is returned if the policy is not valid.
+func Filter[T any](ctx context.Context, src Pool[T], policy Policy, pred func(T) bool) (Pool[T], error) {
+	dst, err := NewPool[T](ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	go transform(ctx, src, dst, "Filter", func(t T) (T, bool) {
+		return t, pred(t)
+	})
+	return dst, nil
+}
+
+// Merge creates a new pool, governed by the given policy, fed with the elements of all the source pools.
+// Each source is read concurrently, from its first available element, so elements of different sources are interleaved
+// as they arrive, while the elements of each source keep their order.
+// Should a source evict elements before they are read, those elements are skipped.
+// Once all the source pools have shut down, the new pool is closed as with CloseAndDrain, so its readers are first
+// delivered the elements it holds. It is closed immediately should the context be cancelled.
+// An error is returned if the policy is not valid.
+func Merge[T any](ctx context.Context, policy Policy, sources ...Pool[T]) (Pool[T], error) {
+	dst, err := NewPool[T](ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		wg.Add(1)
+		go func(src Pool[T]) {
+			defer wg.Done()
+			for t := range src.ReadFrom(ctx, FromEarliestFollow) {
+				if err := dst.Append(ctx, t); err != nil {
+					// the merged pool has shutdown, so reading the other sources ends too
+					cancel()
+					return
+				}
+			}
+		}(src)
+	}
+	go func() {
+		defer cancel()
+		wg.Wait()
+		_ = dst.CloseAndDrain(ctx)
+	}()
+	return dst, nil
+}
+
+// transform reads every element from the source pool, feeding those fn accepts into the destination pool,
+// until either pool shuts down or the context is cancelled, then drains and closes the destination pool.
+// Elements evicted from the source before being read are jumped, rather than ending the read.
+// An element for which fn panics is dropped, rather than taking down the transform, the panic being logged under the
+// given name.
+func transform[A, B any](ctx context.Context, src Pool[A], dst Pool[B], name string, fn func(A) (B, bool)) {
+	// drained with the outer context, as the read context is cancelled once the read ends
+	defer func() {
+		_ = dst.CloseAndDrain(ctx)
+	}()
+	rctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	logger := loggerOf(src)
+	for a := range src.ReadFrom(rctx, FromEarliestFollow) {
+		b, ok := guardTransform(logger, name, fn, a)
+		if !ok {
+			continue
+		}
+		if err := dst.Append(rctx, b); err != nil {
+			return
+		}
+	}
+}
+
+// guardTransform calls fn with the given element, recovering and logging any panic it raises as a rejection of the element.
+func guardTransform[A, B any](logger Logger, name string, fn func(A) (B, bool), a A) (b B, ok bool) {
+	recoverCallback(logger, name, func() {
+		b, ok = fn(a)
+	})
+	return b, ok
+}
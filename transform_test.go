@@ -0,0 +1,250 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMapFilter_Composed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := MustNewPool[int](ctx, Policy{Count: 20})
+	mapped, err := Map[int, string](ctx, src, Policy{Count: 20}, func(i int) string { return strconv.Itoa(i * 10) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := Filter[string](ctx, mapped, Policy{Count: 20}, func(s string) bool { return len(s) > 2 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := src.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitForLast(t, ctx, filtered, "190")
+	got := filtered.Snapshot()
+	if len(got) != 10 || got[0] != "100" || got[9] != "190" {
+		t.Fatalf("expected 100 to 190, in order, found %v", got)
+	}
+
+	src.Close()
+	wctx, wcancel := context.WithTimeout(ctx, 2*time.Second)
+	defer wcancel()
+	if err := filtered.WaitForCloseContext(wctx); err != nil {
+		t.Fatalf("expected the derived pools to close with their source, found %v", err)
+	}
+}
+
+func TestMap_SurvivesSourceEviction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src, err := NewPoolWithOptions[int](ctx, Policy{Size: 10}, WithSizer(func(i int) uint64 { return uint64(i) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := Map[int, int](ctx, src, Policy{Count: 200}, func(i int) int { return i * 2 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Append(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	waitForLast(t, ctx, dst, 2)
+	// too large to be held, so the mapping reader, waiting on its offset, finds it evicted
+	if err := src.Append(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Append(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForLast(t, ctx, dst, 10)
+}
+
+// waitForLast waits for want to be the last element in the pool.
+func waitForLast[T comparable](t *testing.T, ctx context.Context, p Pool[T], want T) {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		if err := p.Ping(ctx); errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("pool closed before %v was fed", want)
+		}
+		if all := p.Snapshot(); len(all) > 0 && all[len(all)-1] == want {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected %v to be fed", want)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestMerge_SurvivesSourceEviction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := MustNewPool[int](ctx, Policy{Count: 2})
+	other := MustNewPool[int](ctx, Policy{Count: 2})
+	dst, err := Merge[int](ctx, Policy{Count: 200}, src, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Append(ctx, -1); err != nil {
+		t.Fatal(err)
+	}
+	waitForLast(t, ctx, dst, -1)
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+	if err := src.FeedSlice(ctx, items); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Append(ctx, 1000); err != nil {
+		t.Fatal(err)
+	}
+	waitForLast(t, ctx, dst, 1000)
+}
+
+func TestFilter_DropsElementsWherePredPanics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := MustNewPool[int](ctx, Policy{Count: 10}, 1, 2, 3)
+	dst, err := Filter[int](ctx, src, Policy{Count: 10}, func(i int) bool {
+		if i == 2 {
+			panic("bad element")
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForLast(t, ctx, dst, 3)
+	if all := dst.Snapshot(); len(all) != 2 || all[0] != 1 {
+		t.Fatalf("expected [1 3], found %v", all)
+	}
+}
+
+func TestMap_PanicIsLogged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := &captureLogger{}
+	src, err := NewPoolWithOptions[int](ctx, Policy{Count: 10}, WithLogger[int](logger), WithData(1, 2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := Map[int, int](ctx, src, Policy{Count: 10}, func(i int) int {
+		if i == 2 {
+			panic("bad element")
+		}
+		return i * 10
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForLast(t, ctx, dst, 30)
+	if all := dst.Snapshot(); len(all) != 2 || all[0] != 10 {
+		t.Fatalf("expected [10 30], found %v", all)
+	}
+	if !logger.contains("Map callback panicked: bad element") {
+		t.Fatalf("expected the panic to be logged, found %q", logger.messages)
+	}
+}
+
+func TestMerge_FiniteSources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := MustNewPool[int](ctx, Policy{Count: 100}, 0, 1, 2, 3, 4)
+	b := MustNewPool[int](ctx, Policy{Count: 100}, 100, 101, 102)
+	merged, err := Merge[int](ctx, Policy{Count: 100}, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := merged.Read(ctx, -1)
+	for i := 5; i < 10; i++ {
+		if err := a.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Append(ctx, 103); err != nil {
+		t.Fatal(err)
+	}
+
+	wctx, wcancel := context.WithTimeout(ctx, 2*time.Second)
+	defer wcancel()
+	if err := merged.WaitForOffset(wctx, 13); err != nil {
+		t.Fatalf("expected all 14 elements of both sources, found %v", merged.Snapshot())
+	}
+	s := merged.Snapshot()
+	nextA, nextB := 0, 100
+	for _, v := range s {
+		switch {
+		case v == nextA:
+			nextA++
+		case v == nextB:
+			nextB++
+		default:
+			t.Fatalf("expected the elements of each source in order, found %v", s)
+		}
+	}
+
+	a.Close()
+	if err := merged.Ping(ctx); err != nil {
+		t.Fatalf("expected the merged pool to stay open while a source is open, found %v", err)
+	}
+	b.Close()
+	// the reader, yet to receive any, is delivered every merged element before the merged pool closes
+	var read []int
+	for v := range r {
+		read = append(read, v)
+	}
+	if len(read) != len(s) {
+		t.Fatalf("expected the reader to read all %d merged elements, found %v", len(s), read)
+	}
+	if err := merged.WaitForCloseContext(wctx); err != nil {
+		t.Fatalf("expected the merged pool to close with its sources, found %v", err)
+	}
+}
+
+func TestMapFilter_FiniteSourceReadInFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := MustNewPool[int](ctx, Policy{Count: 100})
+	mapped, err := Map[int, int](ctx, src, Policy{Count: 100}, func(i int) int { return i * 10 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := Filter[int](ctx, mapped, Policy{Count: 100}, func(i int) bool { return i%20 == 0 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := mapped.Read(ctx, -1)
+	fr := filtered.Read(ctx, -1)
+	for i := 0; i < 50; i++ {
+		if err := src.Append(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the derived pools close as the source does, their readers relying on them draining as they close
+	if err := src.CloseAndDrain(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var mapRead, filterRead []int
+	for v := range mr {
+		mapRead = append(mapRead, v)
+	}
+	for v := range fr {
+		filterRead = append(filterRead, v)
+	}
+	if len(mapRead) != 50 || mapRead[49] != 490 {
+		t.Fatalf("expected every mapped element read, found %v", mapRead)
+	}
+	if len(filterRead) != 25 || filterRead[24] != 480 {
+		t.Fatalf("expected every filtered element read, found %v", filterRead)
+	}
+}
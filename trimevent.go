@@ -0,0 +1,26 @@
+package pools
+
+// trimEventBuffer is the number of TrimEvents held for a slow listener before further events are dropped.
+const trimEventBuffer = 16
+
+// TrimEvent reports the elements the Policy, or a RetentionPolicy, removed from a pool in a single trim,
+// or those removed by Flush or Reset.
+type TrimEvent struct {
+	// Count is the number of elements removed.
+	Count int
+	// FromOffset is the offset of the first element removed.
+	FromOffset int64
+	// ToOffset is the offset following the last element removed.
+	ToOffset int64
+}
+
+// record extends the event to include the removed offset.
+func (e *TrimEvent) record(offset int64) {
+	if e.Count == 0 || offset < e.FromOffset {
+		e.FromOffset = offset
+	}
+	if e.Count == 0 || offset >= e.ToOffset {
+		e.ToOffset = offset + 1
+	}
+	e.Count++
+}
@@ -0,0 +1,153 @@
+package pools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// errCodecPanicked is the error of a value whose Codec panicked while encoding or decoding it.
+var errCodecPanicked = errors.New("codec panicked")
+
+// Codec converts values of T to and from the bytes held in a byte pool.
+type Codec[T any] interface {
+	// Encode returns the bytes of the given value.
+	Encode(t T) ([]byte, error)
+	// Decode returns the value of the given bytes.
+	Decode(b []byte) (T, error)
+}
+
+// JSONCodec is a Codec encoding values as JSON.
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(t T) ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (JSONCodec[T]) Decode(b []byte) (T, error) {
+	var t T
+	err := json.Unmarshal(b, &t)
+	return t, err
+}
+
+// TypedPool feeds and reads values of T through a byte pool, encoding each value with its Codec.
+// The pool holds only the encoded bytes, so its Policy Size applies to the encoded size of the values.
+// Should the Codec panic, the panic is recovered and logged to the Logger of the pool, and the value fails to encode,
+// or decode.
+type TypedPool[T any] struct {
+	pool  Pool[[]byte]
+	codec Codec[T]
+}
+
+// NewTypedPool creates a TypedPool of the given byte pool, encoding with the given codec.
+func NewTypedPool[T any](p Pool[[]byte], codec Codec[T]) *TypedPool[T] {
+	return &TypedPool[T]{pool: p, codec: codec}
+}
+
+// Pool returns the byte pool holding the encoded values.
+func (tp *TypedPool[T]) Pool() Pool[[]byte] {
+	return tp.pool
+}
+
+// Append encodes the value and appends it to the pool, in the same way as Pool Append.
+func (tp *TypedPool[T]) Append(ctx context.Context, t T) error {
+	b, err := tp.encode(t)
+	if err != nil {
+		return fmt.Errorf("append failed as %w", err)
+	}
+	return tp.pool.Append(ctx, b)
+}
+
+// Feed encodes and appends each value received from the given channel, until the channel is closed, the context is
+// cancelled or the pool shuts down. The returned channel receives any error which ended the feed early, including a
+// value failing to encode, or ErrPoolClosed once the pool shuts down, and is closed once the feed ends.
+func (tp *TypedPool[T]) Feed(ctx context.Context, ch <-chan T) <-chan error {
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		// the pool is watched, so the feed ends as it shuts down, rather than once another value is received
+		wctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		closed := make(chan struct{})
+		go func() {
+			if tp.pool.WaitForCloseContext(wctx) == nil {
+				close(closed)
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-closed:
+				errc <- fmt.Errorf("feed failed as %w", ErrPoolClosed)
+				return
+			case t, ok := <-ch:
+				if !ok {
+					return
+				}
+				if err := tp.Append(ctx, t); err != nil {
+					if ctx.Err() == nil {
+						errc <- err
+					}
+					return
+				}
+			}
+		}
+	}()
+	return errc
+}
+
+// Read reads the values of the pool, starting at the given offset, in the same way as Pool ReadWithErr, decoding
+// each in turn. A value which fails to decode ends the read, the error naming its offset, so reading may be resumed
+// past it.
+func (tp *TypedPool[T]) Read(ctx context.Context, offset int64) (<-chan T, <-chan error) {
+	ch := make(chan T)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(ch)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		c := tp.pool.Cursor(ctx, offset)
+		for {
+			at := c.Offset()
+			b, ok := c.Next()
+			if !ok {
+				if err := c.Err(); err != nil {
+					errc <- err
+				}
+				return
+			}
+			t, err := tp.decode(b)
+			if err != nil {
+				errc <- fmt.Errorf("decode failed at offset %d as %w", at, err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- t:
+			}
+		}
+	}()
+	return ch, errc
+}
+
+// encode encodes the value with the codec, recovering and logging any panic it raises as a failure to encode.
+func (tp *TypedPool[T]) encode(t T) (b []byte, err error) {
+	err = errCodecPanicked
+	recoverCallback(loggerOf(tp.pool), "Encode", func() {
+		b, err = tp.codec.Encode(t)
+	})
+	return b, err
+}
+
+// decode decodes the bytes with the codec, recovering and logging any panic it raises as a failure to decode.
+func (tp *TypedPool[T]) decode(b []byte) (t T, err error) {
+	err = errCodecPanicked
+	recoverCallback(loggerOf(tp.pool), "Decode", func() {
+		t, err = tp.codec.Decode(b)
+	})
+	return t, err
+}
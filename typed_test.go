@@ -0,0 +1,134 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type typedRecord struct {
+	Name  string
+	Count int
+}
+
+func TestTypedPool_RoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tp := NewTypedPool[typedRecord](MustNewPool[[]byte](ctx, Policy{Count: 10}), JSONCodec[typedRecord]{})
+	want := []typedRecord{{"one", 1}, {"two", 2}, {"three", 3}}
+	if err := tp.Append(ctx, want[0]); err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan typedRecord)
+	errc := tp.Feed(ctx, ch)
+	ch <- want[1]
+	ch <- want[2]
+	close(ch)
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	rctx, rcancel := context.WithCancel(ctx)
+	defer rcancel()
+	r, rerrc := tp.Read(rctx, 0)
+	for _, w := range want {
+		if v := <-r; v != w {
+			t.Fatalf("expected %+v, found %+v", w, v)
+		}
+	}
+	// the pool holds the encoded values
+	if b := tp.Pool().Snapshot()[1]; string(b) != `{"Name":"two","Count":2}` {
+		t.Fatalf("expected the JSON encoding, found %s", b)
+	}
+	rcancel()
+	for range r {
+	}
+	if err := <-rerrc; err != nil {
+		t.Fatalf("expected no error from a cancelled read, found %v", err)
+	}
+}
+
+func TestTypedPool_DecodeError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[[]byte](ctx, Policy{Count: 10}, []byte(`{"Name":"one"}`), []byte("not json"))
+	r, errc := NewTypedPool[typedRecord](p, JSONCodec[typedRecord]{}).Read(ctx, 0)
+	if v := <-r; v.Name != "one" {
+		t.Fatalf("expected one, found %+v", v)
+	}
+	for range r {
+	}
+	if err := <-errc; err == nil || !strings.Contains(err.Error(), "offset 1") {
+		t.Fatalf("expected a decode error at offset 1, found %v", err)
+	}
+}
+
+func TestTypedPool_FeedEndsAsPoolCloses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[[]byte](ctx, Policy{Count: 10})
+	// the channel is never sent to, so the feed can only end by noticing the pool has closed
+	errc := NewTypedPool[typedRecord](p, JSONCodec[typedRecord]{}).Feed(ctx, make(chan typedRecord))
+	p.Close()
+	p.WaitForClose()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("expected ErrPoolClosed, found %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the feed to end as the pool closed")
+	}
+}
+
+// panicCodec panics encoding or decoding a record named "panic".
+type panicCodec struct {
+	JSONCodec[typedRecord]
+}
+
+func (c panicCodec) Encode(r typedRecord) ([]byte, error) {
+	if r.Name == "panic" {
+		panic("bad record")
+	}
+	return c.JSONCodec.Encode(r)
+}
+
+func (c panicCodec) Decode(b []byte) (typedRecord, error) {
+	if string(b) == `{"Name":"panic","Count":0}` {
+		panic("bad bytes")
+	}
+	return c.JSONCodec.Decode(b)
+}
+
+func TestTypedPool_CodecPanicIsRecovered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := &captureLogger{}
+	p, err := NewPoolWithOptions[[]byte](ctx, Policy{Count: 10}, WithLogger[[]byte](logger),
+		WithData([]byte(`{"Name":"one","Count":1}`), []byte(`{"Name":"panic","Count":0}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp := NewTypedPool[typedRecord](p, panicCodec{})
+	if err := tp.Append(ctx, typedRecord{Name: "panic"}); err == nil {
+		t.Fatal("expected a value whose codec panics to fail to append")
+	}
+	if !logger.contains("Encode callback panicked: bad record") {
+		t.Fatalf("expected the encode panic to be logged, found %q", logger.messages)
+	}
+
+	r, errc := tp.Read(ctx, 0)
+	if v := <-r; v.Name != "one" {
+		t.Fatalf("expected one, found %+v", v)
+	}
+	for range r {
+	}
+	if err := <-errc; err == nil || !strings.Contains(err.Error(), "offset 1") {
+		t.Fatalf("expected a decode error at offset 1, found %v", err)
+	}
+	if !logger.contains("Decode callback panicked: bad bytes") {
+		t.Fatalf("expected the decode panic to be logged, found %q", logger.messages)
+	}
+}
@@ -0,0 +1,64 @@
+package pools
+
+import (
+	"context"
+	"io"
+)
+
+// defaultChunkSize is the chunk size used by a pool Writer when none is given.
+const defaultChunkSize = 4096
+
+// NewWriter creates a Writer which feeds the bytes written to it into the given pool, in chunks of chunkSize.
+// Bytes are buffered until a full chunk is written. Closing the Writer feeds any remaining, partial chunk.
+// The Writer does not close the pool.
+func NewWriter(ctx context.Context, p Pool[[]byte], chunkSize int) io.WriteCloser {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	return &poolWriter{
+		ctx:       ctx,
+		pool:      p,
+		chunkSize: chunkSize,
+	}
+}
+
+type poolWriter struct {
+	ctx       context.Context
+	pool      Pool[[]byte]
+	chunkSize int
+	buf       []byte
+}
+
+func (w *poolWriter) Write(b []byte) (int, error) {
+	var n int
+	for len(b) > 0 {
+		l := w.chunkSize - len(w.buf)
+		if l > len(b) {
+			l = len(b)
+		}
+		w.buf = append(w.buf, b[:l]...)
+		b = b[l:]
+		n += l
+		if len(w.buf) < w.chunkSize {
+			continue
+		}
+		if err := w.flush(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (w *poolWriter) Close() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+// flush feeds the buffered bytes into the pool as a single chunk.
+func (w *poolWriter) flush() error {
+	chunk := w.buf
+	w.buf = make([]byte, 0, w.chunkSize)
+	return w.pool.Append(w.ctx, chunk)
+}
@@ -0,0 +1,44 @@
+package pools
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriter_CopiesInChunks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := MustNewPool[[]byte](ctx, Policy{Count: 10})
+	w := NewWriter(ctx, p, 4)
+	if _, err := io.Copy(w, strings.NewReader("hello world")); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.Len(); n != 2 {
+		t.Fatalf("expected 2 full chunks before closing, found %d", n)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"hell", "o wo", "rld"}
+	chunks := p.Snapshot()
+	if len(chunks) != len(want) {
+		t.Fatalf("expected chunks %q, found %q", want, chunks)
+	}
+	for i, chunk := range chunks {
+		if string(chunk) != want[i] {
+			t.Fatalf("expected chunks %q, found %q", want, chunks)
+		}
+	}
+}
+
+func TestWriter_ClosedPool(t *testing.T) {
+	p := MustNewPool[[]byte](context.Background(), Policy{Count: 10})
+	p.Close()
+	p.WaitForClose()
+	w := NewWriter(context.Background(), p, 4)
+	if _, err := w.Write([]byte("hello")); err == nil {
+		t.Fatal("expected writing a full chunk to a closed pool to fail")
+	}
+}